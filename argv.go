@@ -0,0 +1,849 @@
+package str
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// ArgvDialect selects the rules used to split a command line into arguments.
+type ArgvDialect int
+
+const (
+	// ArgvAuto is ToArgv's historical behaviour for the current OS:
+	// ArgvLegacyWindows on Windows and ArgvLegacy elsewhere.
+	ArgvAuto ArgvDialect = iota
+	// ArgvLegacy splits on unquoted whitespace. Single and double quotes
+	// group and a backslash escapes the next byte anywhere.
+	ArgvLegacy
+	// ArgvLegacyWindows is ArgvLegacy except that backslashes are literal
+	// unless they precede a double quote.
+	ArgvLegacyWindows
+	// ArgvPOSIX follows POSIX sh word splitting and quote removal, including
+	// bash's $'...' strings, backslash-newline continuations and # comments.
+	// Expansions and operators such as | or ; are not interpreted.
+	ArgvPOSIX
+	// ArgvWindows follows the rules of CommandLineToArgvW, which most Windows
+	// programs use to parse their command line.
+	ArgvWindows
+	// ArgvCmd is ArgvWindows for a command line that first passes through
+	// cmd.exe, which removes the ^ escape from the following character
+	// outside double quotes.
+	ArgvCmd
+)
+
+// ArgvOptions configures ParseArgvWith.
+type ArgvOptions struct {
+	// Dialect selects the parsing rules.
+	Dialect ArgvDialect
+	// Lookup, when set, enables shell expansion of $name, ${name},
+	// ${name:-word}, ${name:+word} and ${name:?message}, and of a leading ~
+	// to the value of HOME. It reports whether name is defined, like
+	// os.LookupEnv. The forms without a colon only test whether name is
+	// defined, not whether it is empty. Expansions are not performed inside
+	// single quotes, and unquoted expansions are split into words on
+	// whitespace. Expansion requires ArgvPOSIX.
+	Lookup func(name string) (string, bool)
+	// NoUnset makes expanding an undefined variable an error, like set -u.
+	NoUnset bool
+	// Glob, when set, is called with each word containing an unquoted *, ?
+	// or [ and the word is replaced by the matches, if any. Quoted glob
+	// characters are escaped with a backslash in the pattern, as expected by
+	// filepath.Glob. Glob requires ArgvPOSIX.
+	Glob func(pattern string) ([]string, error)
+}
+
+var (
+	// ErrUnterminatedQuote is the ArgvError reason for a quote without its
+	// closing quote.
+	ErrUnterminatedQuote = errors.New("starting quote has no ending quote")
+	// ErrTrailingEscape is the ArgvError reason for an escape character at
+	// the end of the string.
+	ErrTrailingEscape = errors.New("escape character at end of string")
+	// ErrUnterminatedBrace is the ArgvError reason for a ${ without its
+	// closing brace.
+	ErrUnterminatedBrace = errors.New("${ has no closing brace")
+	// ErrBadSubstitution is the ArgvError reason for a malformed ${...}.
+	ErrBadSubstitution = errors.New("bad substitution")
+	// ErrUndefinedVariable is wrapped by the ArgvError reason for an
+	// undefined variable when ArgvOptions.NoUnset is set or for a
+	// ${name:?message} expansion.
+	ErrUndefinedVariable = errors.New("undefined variable")
+	// ErrExpansionDialect is the ArgvError reason for requesting expansion
+	// or globbing from a dialect other than ArgvPOSIX.
+	ErrExpansionDialect = errors.New("expansion requires ArgvPOSIX")
+)
+
+// ArgvError is returned by ParseArgv when s cannot be split into arguments.
+type ArgvError struct {
+	// Offset is the byte offset of the offending character.
+	Offset int
+	// Err is the reason, such as ErrUnterminatedQuote.
+	Err error
+}
+
+func (e *ArgvError) Error() string {
+	return fmt.Sprintf("argv:%d: %v", e.Offset, e.Err)
+}
+
+// Unwrap returns the reason.
+func (e *ArgvError) Unwrap() error {
+	return e.Err
+}
+
+// ParseArgv converts string s into an argv for exec like ToArgv but returns
+// an *ArgvError for malformed input instead of panicking.
+func ParseArgv(s string) ([]string, error) {
+	return ParseArgvWith(s, ArgvOptions{})
+}
+
+// ParseArgvWith converts string s into an argv for exec using the rules
+// selected by opts.
+func ParseArgvWith(s string, opts ArgvOptions) ([]string, error) {
+	if opts.Dialect != ArgvPOSIX && (opts.Lookup != nil || opts.Glob != nil) {
+		return nil, &ArgvError{Offset: 0, Err: ErrExpansionDialect}
+	}
+	switch opts.Dialect {
+	case ArgvLegacy:
+		return parseArgvLegacy(s, false)
+	case ArgvLegacyWindows:
+		return parseArgvLegacy(s, true)
+	case ArgvPOSIX:
+		return parseArgvPOSIX(s, opts)
+	case ArgvWindows:
+		return parseArgvWindows(s), nil
+	case ArgvCmd:
+		return parseArgvWindows(unescapeCmd(s)), nil
+	}
+	return parseArgvLegacy(s, runtime.GOOS == "windows")
+}
+
+// JoinArgv quotes each argument of argv for dialect and joins them with
+// spaces into a command line that ParseArgvWith splits back into argv.
+// See QuoteArgv.
+func JoinArgv(argv []string, dialect ArgvDialect) string {
+	return strings.Join(QuoteArgv(argv, dialect), " ")
+}
+
+// QuoteArg quotes arg so ParseArgvWith with the same dialect reads it back
+// as a single argument, leaving it bare when that is safe. For ArgvWindows
+// and ArgvCmd, arg is quoted as an argument rather than a program name.
+func QuoteArg(arg string, dialect ArgvDialect) string {
+	switch resolveArgvDialect(dialect) {
+	case ArgvLegacyWindows:
+		return quoteArgLegacyWindows(arg)
+	case ArgvPOSIX:
+		return quoteArgPOSIX(arg)
+	case ArgvWindows:
+		return quoteArgWindows(arg)
+	case ArgvCmd:
+		return escapeCmd(quoteArgWindows(arg))
+	}
+	return quoteArgLegacy(arg)
+}
+
+// QuoteArgv quotes every argument of argv with QuoteArg. For ArgvWindows
+// and ArgvCmd the first argument is quoted as a program name, which cannot
+// contain double quotes. ArgvLegacyWindows cannot represent a backslash
+// directly before a double quote; all other arguments round-trip exactly.
+func QuoteArgv(argv []string, dialect ArgvDialect) []string {
+	dialect = resolveArgvDialect(dialect)
+	result := []string{}
+	for i, arg := range argv {
+		if i > 0 || strings.Contains(arg, `"`) {
+			result = append(result, QuoteArg(arg, dialect))
+			continue
+		}
+		switch dialect {
+		case ArgvWindows:
+			result = append(result, quoteProgramWindows(arg))
+		case ArgvCmd:
+			result = append(result, escapeCmd(quoteProgramWindows(arg)))
+		default:
+			result = append(result, QuoteArg(arg, dialect))
+		}
+	}
+	return result
+}
+
+func resolveArgvDialect(dialect ArgvDialect) ArgvDialect {
+	if dialect != ArgvAuto {
+		return dialect
+	}
+	if runtime.GOOS == "windows" {
+		return ArgvLegacyWindows
+	}
+	return ArgvLegacy
+}
+
+// quoteArgLegacy escapes whitespace, quotes and backslashes with a
+// backslash.
+func quoteArgLegacy(arg string) string {
+	if arg == "" {
+		return `""`
+	}
+	quoted := []byte{}
+	for i := 0; i < len(arg); i++ {
+		switch arg[i] {
+		case ' ', '\t', '\'', '"', '\\':
+			quoted = append(quoted, '\\')
+		}
+		quoted = append(quoted, arg[i])
+	}
+	return string(quoted)
+}
+
+// quoteArgLegacyWindows single quotes runs without single quotes and double
+// quotes runs of single quotes.
+func quoteArgLegacyWindows(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t'\"") {
+		return arg
+	}
+	quoted := ""
+	for arg != "" {
+		n := strings.IndexByte(arg, '\'')
+		switch {
+		case n < 0:
+			n = len(arg)
+			fallthrough
+		case n > 0:
+			quoted += "'" + arg[:n] + "'"
+		default:
+			n = len(arg) - len(strings.TrimLeft(arg, "'"))
+			quoted += `"` + arg[:n] + `"`
+		}
+		arg = arg[n:]
+	}
+	if quoted == "" {
+		return `""`
+	}
+	return quoted
+}
+
+// quoteArgPOSIX single quotes arg unless it only has characters that are
+// never special to a shell.
+func quoteArgPOSIX(arg string) string {
+	safe := arg != ""
+	for i := 0; i < len(arg) && safe; i++ {
+		c := arg[i]
+		safe = c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+			strings.IndexByte("@%+=:,./_-", c) >= 0
+	}
+	if safe {
+		return arg
+	}
+	return "'" + strings.Replace(arg, "'", `'\''`, -1) + "'"
+}
+
+// quoteArgWindows quotes arg for CommandLineToArgvW, doubling backslashes
+// that precede a double quote or the closing quote.
+func quoteArgWindows(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\n\v\"") {
+		return arg
+	}
+	quoted := []byte{'"'}
+	slashes := 0
+	for i := 0; i < len(arg); i++ {
+		switch arg[i] {
+		case '\\':
+			slashes++
+		case '"':
+			quoted = append(quoted, strings.Repeat(`\`, slashes+1)...)
+			slashes = 0
+		default:
+			slashes = 0
+		}
+		quoted = append(quoted, arg[i])
+	}
+	quoted = append(quoted, strings.Repeat(`\`, slashes)...)
+	return string(append(quoted, '"'))
+}
+
+// quoteProgramWindows quotes a program name, which CommandLineToArgvW reads
+// without backslash escapes.
+func quoteProgramWindows(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t") {
+		return arg
+	}
+	return `"` + arg + `"`
+}
+
+// escapeCmd prefixes cmd.exe metacharacters with ^. Double quotes are
+// escaped too so cmd.exe never switches to quoted mode.
+func escapeCmd(s string) string {
+	escaped := []byte{}
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(`()%!^"<>&|`, s[i]) >= 0 {
+			escaped = append(escaped, '^')
+		}
+		escaped = append(escaped, s[i])
+	}
+	return string(escaped)
+}
+
+// unescapeCmd removes the ^ escapes cmd.exe removes before running a
+// command.
+func unescapeCmd(s string) string {
+	unescaped := []byte{}
+	inQuote := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '"':
+			inQuote = !inQuote
+		case s[i] == '^' && !inQuote:
+			i++
+			if i == len(s) {
+				return string(unescaped)
+			}
+		}
+		unescaped = append(unescaped, s[i])
+	}
+	return string(unescaped)
+}
+
+// parseArgvLegacy splits s on unquoted whitespace. On Windows backslashes
+// are kept unless they precede a double quote, elsewhere they escape the
+// next byte.
+func parseArgvLegacy(s string, windows bool) ([]string, error) {
+	const (
+		InArg = iota
+		InArgQuote
+		OutOfArg
+	)
+	currentState := OutOfArg
+	currentQuoteChar := "\x00" // to distinguish between ' and " quotations
+	// this allows to use "foo'bar"
+	currentQuotePos := 0
+	currentArg := ""
+	argv := []string{}
+
+	isQuote := func(c string) bool {
+		return c == `"` || c == `'`
+	}
+
+	isEscape := func(c string) bool {
+		return c == `\`
+	}
+
+	isWhitespace := func(c string) bool {
+		return c == " " || c == "\t"
+	}
+
+	L := len(s)
+	for i := 0; i < L; i++ {
+		c := s[i : i+1]
+
+		if isQuote(c) {
+			switch currentState {
+			case OutOfArg:
+				currentArg = ""
+				fallthrough
+			case InArg:
+				currentState = InArgQuote
+				currentQuoteChar = c
+				currentQuotePos = i
+
+			case InArgQuote:
+				if c == currentQuoteChar {
+					currentState = InArg
+				} else {
+					currentArg += c
+				}
+			}
+
+		} else if isWhitespace(c) {
+			switch currentState {
+			case InArg:
+				argv = append(argv, currentArg)
+				currentState = OutOfArg
+			case InArgQuote:
+				currentArg += c
+			case OutOfArg:
+				// nothing
+			}
+
+		} else if isEscape(c) {
+			switch currentState {
+			case OutOfArg:
+				currentArg = ""
+				currentState = InArg
+				fallthrough
+			case InArg:
+				fallthrough
+			case InArgQuote:
+				if i == L-1 {
+					if windows {
+						// just add \ to end for windows
+						currentArg += c
+					} else {
+						return nil, &ArgvError{Offset: i, Err: ErrTrailingEscape}
+					}
+				} else {
+					if windows {
+						peek := s[i+1 : i+2]
+						if peek != `"` {
+							currentArg += c
+						}
+					} else {
+						i++
+						c = s[i : i+1]
+						currentArg += c
+					}
+				}
+			}
+		} else {
+			switch currentState {
+			case InArg, InArgQuote:
+				currentArg += c
+
+			case OutOfArg:
+				currentArg = ""
+				currentArg += c
+				currentState = InArg
+			}
+		}
+	}
+
+	if currentState == InArg {
+		argv = append(argv, currentArg)
+	} else if currentState == InArgQuote {
+		return nil, &ArgvError{Offset: currentQuotePos, Err: ErrUnterminatedQuote}
+	}
+
+	return argv, nil
+}
+
+// parseArgvPOSIX splits s like a POSIX shell splits a simple command into
+// words, performing the expansions enabled by opts.
+func parseArgvPOSIX(s string, opts ArgvOptions) ([]string, error) {
+	p := &posixParser{s: s, opts: opts, argv: []string{}}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			if err := p.endArg(); err != nil {
+				return nil, err
+			}
+		case c == '#' && !p.inArg:
+			for i+1 < len(s) && s[i+1] != '\n' {
+				i++
+			}
+		case c == '\\':
+			if i == len(s)-1 {
+				return nil, &ArgvError{Offset: i, Err: ErrTrailingEscape}
+			}
+			i++
+			if s[i] != '\n' {
+				p.appendQuoted(s[i : i+1])
+			}
+		case c == '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				return nil, &ArgvError{Offset: i, Err: ErrUnterminatedQuote}
+			}
+			p.appendQuoted(s[i+1 : i+1+end])
+			i += end + 1
+		case c == '$' && i+1 < len(s) && s[i+1] == '\'':
+			value, end, err := parseANSICQuote(s, i)
+			if err != nil {
+				return nil, err
+			}
+			p.appendQuoted(string(value))
+			i = end
+		case c == '"':
+			j := i + 1
+			quoted := []byte{}
+			for ; j < len(s) && s[j] != '"'; j++ {
+				if s[j] == '\\' && j+1 < len(s) {
+					switch s[j+1] {
+					case '$', '`', '"', '\\':
+						j++
+					case '\n':
+						j++
+						continue
+					}
+				} else if s[j] == '$' && p.opts.Lookup != nil {
+					value, end, err := p.expandParam(j)
+					if err != nil {
+						return nil, err
+					}
+					if end > j {
+						quoted = append(quoted, value...)
+						j = end
+						continue
+					}
+				}
+				quoted = append(quoted, s[j])
+			}
+			if j == len(s) {
+				return nil, &ArgvError{Offset: i, Err: ErrUnterminatedQuote}
+			}
+			p.appendQuoted(string(quoted))
+			i = j
+		case c == '$' && p.opts.Lookup != nil:
+			value, end, err := p.expandParam(i)
+			if err != nil {
+				return nil, err
+			}
+			if end == i {
+				p.appendUnquoted(c)
+				break
+			}
+			for k := 0; k < len(value); k++ {
+				if value[k] == ' ' || value[k] == '\t' || value[k] == '\n' {
+					if err := p.endArg(); err != nil {
+						return nil, err
+					}
+				} else {
+					p.appendUnquoted(value[k])
+				}
+			}
+			i = end
+		case c == '~' && !p.inArg && p.opts.Lookup != nil && (i+1 == len(s) || strings.IndexByte("/ \t\n", s[i+1]) >= 0):
+			if home, ok := p.opts.Lookup("HOME"); ok {
+				p.appendQuoted(home)
+			} else {
+				p.appendUnquoted(c)
+			}
+		default:
+			p.appendUnquoted(c)
+		}
+	}
+	if err := p.endArg(); err != nil {
+		return nil, err
+	}
+	return p.argv, nil
+}
+
+// posixParser accumulates the words of a POSIX command line. pattern
+// mirrors arg with quoted glob characters escaped.
+type posixParser struct {
+	s       string
+	opts    ArgvOptions
+	argv    []string
+	arg     []byte
+	pattern []byte
+	inArg   bool
+	glob    bool
+}
+
+func (p *posixParser) appendQuoted(s string) {
+	p.arg = append(p.arg, s...)
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(`*?[\`, s[i]) >= 0 {
+			p.pattern = append(p.pattern, '\\')
+		}
+		p.pattern = append(p.pattern, s[i])
+	}
+	p.inArg = true
+}
+
+func (p *posixParser) appendUnquoted(c byte) {
+	p.arg = append(p.arg, c)
+	p.pattern = append(p.pattern, c)
+	p.glob = p.glob || c == '*' || c == '?' || c == '['
+	p.inArg = true
+}
+
+// endArg adds the current word to argv, replaced by its glob matches if it
+// has any.
+func (p *posixParser) endArg() error {
+	if !p.inArg {
+		return nil
+	}
+	var matches []string
+	if p.glob && p.opts.Glob != nil {
+		var err error
+		if matches, err = p.opts.Glob(string(p.pattern)); err != nil {
+			return &ArgvError{Offset: len(p.s), Err: err}
+		}
+	}
+	if len(matches) > 0 {
+		p.argv = append(p.argv, matches...)
+	} else {
+		p.argv = append(p.argv, string(p.arg))
+	}
+	p.arg, p.pattern = p.arg[:0], p.pattern[:0]
+	p.inArg, p.glob = false, false
+	return nil
+}
+
+// expandParam expands the parameter starting with the $ at s[start]. It
+// returns the value and the offset of the last byte consumed, which is
+// start if s[start:] is not a parameter expansion.
+func (p *posixParser) expandParam(start int) (string, int, error) {
+	s := p.s
+	if start+1 < len(s) && s[start+1] != '{' {
+		end := start + 1
+		for end < len(s) && isNameByte(s[end], end == start+1) {
+			end++
+		}
+		if end == start+1 {
+			return "", start, nil
+		}
+		value, err := p.lookup(s[start+1:end], start)
+		return value, end - 1, err
+	}
+	if start+1 == len(s) {
+		return "", start, nil
+	}
+
+	// find the closing brace, allowing nested ${...} in the word
+	depth := 0
+	end := start + 2
+	for ; end < len(s); end++ {
+		if s[end] == '{' && s[end-1] == '$' {
+			depth++
+		} else if s[end] == '}' {
+			if depth == 0 {
+				break
+			}
+			depth--
+		}
+	}
+	if end == len(s) {
+		return "", 0, &ArgvError{Offset: start, Err: ErrUnterminatedBrace}
+	}
+
+	expr := s[start+2 : end]
+	n := 0
+	for n < len(expr) && isNameByte(expr[n], n == 0) {
+		n++
+	}
+	name, op := expr[:n], expr[n:]
+	if name == "" {
+		return "", 0, &ArgvError{Offset: start, Err: ErrBadSubstitution}
+	}
+	if op == "" {
+		value, err := p.lookup(name, start)
+		return value, end, err
+	}
+
+	colon := op[0] == ':'
+	if colon {
+		op = op[1:]
+	}
+	if op == "" || strings.IndexByte("-?+", op[0]) < 0 {
+		return "", 0, &ArgvError{Offset: start, Err: ErrBadSubstitution}
+	}
+	value, ok := p.opts.Lookup(name)
+	set := ok && (value != "" || !colon)
+	// As in sh, the word is only expanded when it is used.
+	var err error
+	switch op[0] {
+	case '-':
+		if !set {
+			value, err = p.expandWord(op[1:], start)
+		}
+	case '+':
+		value = ""
+		if set {
+			value, err = p.expandWord(op[1:], start)
+		}
+	case '?':
+		if !set {
+			word, err := p.expandWord(op[1:], start)
+			if err != nil {
+				return "", 0, err
+			}
+			if word == "" {
+				word = "parameter null or not set"
+			}
+			return "", 0, &ArgvError{Offset: start, Err: fmt.Errorf("%w %s: %s", ErrUndefinedVariable, name, word)}
+		}
+	}
+	if err != nil {
+		return "", 0, err
+	}
+	return value, end, nil
+}
+
+// expandWord expands the parameters in the word of a ${name:-word} style
+// expansion.
+func (p *posixParser) expandWord(word string, offset int) (string, error) {
+	if !strings.Contains(word, "$") {
+		return word, nil
+	}
+	sub := &posixParser{s: word, opts: p.opts}
+	result := []byte{}
+	for i := 0; i < len(word); i++ {
+		if word[i] == '$' {
+			value, end, err := sub.expandParam(i)
+			if err != nil {
+				if aerr, ok := err.(*ArgvError); ok {
+					aerr.Offset += offset
+				}
+				return "", err
+			}
+			if end > i {
+				result = append(result, value...)
+				i = end
+				continue
+			}
+		}
+		result = append(result, word[i])
+	}
+	return string(result), nil
+}
+
+// lookup returns the value of variable name, which is an error for
+// undefined variables if opts.NoUnset is set.
+func (p *posixParser) lookup(name string, offset int) (string, error) {
+	value, ok := p.opts.Lookup(name)
+	if !ok && p.opts.NoUnset {
+		return "", &ArgvError{Offset: offset, Err: fmt.Errorf("%w %s", ErrUndefinedVariable, name)}
+	}
+	return value, nil
+}
+
+// isNameByte reports whether c may appear in a variable name.
+func isNameByte(c byte, first bool) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || !first && c >= '0' && c <= '9'
+}
+
+// parseANSICQuote decodes the bash $'...' string starting at s[start]. It
+// returns the decoded value and the offset of the closing quote.
+func parseANSICQuote(s string, start int) ([]byte, int, error) {
+	value := []byte{}
+	for i := start + 2; i < len(s); i++ {
+		c := s[i]
+		if c == '\'' {
+			return value, i, nil
+		}
+		if c != '\\' {
+			value = append(value, c)
+			continue
+		}
+		if i == len(s)-1 {
+			break
+		}
+		i++
+		switch c = s[i]; c {
+		case 'a':
+			value = append(value, '\a')
+		case 'b':
+			value = append(value, '\b')
+		case 'e', 'E':
+			value = append(value, 0x1B)
+		case 'f':
+			value = append(value, '\f')
+		case 'n':
+			value = append(value, '\n')
+		case 'r':
+			value = append(value, '\r')
+		case 't':
+			value = append(value, '\t')
+		case 'v':
+			value = append(value, '\v')
+		case '\\', '\'', '"', '?':
+			value = append(value, c)
+		case 'c':
+			if i+1 < len(s) {
+				i++
+				value = append(value, s[i]&0x1F)
+			}
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			n, digits := parseDigits(s[i:], 8, 3)
+			value = append(value, byte(n))
+			i += digits - 1
+		case 'x', 'u', 'U':
+			size := map[byte]int{'x': 2, 'u': 4, 'U': 8}[c]
+			n, digits := parseDigits(s[i+1:], 16, size)
+			if digits == 0 {
+				value = append(value, '\\', c)
+				break
+			}
+			if c == 'x' {
+				value = append(value, byte(n))
+			} else {
+				value = append(value, string(rune(n))...)
+			}
+			i += digits
+		default:
+			value = append(value, '\\', c)
+		}
+	}
+	return nil, 0, &ArgvError{Offset: start, Err: ErrUnterminatedQuote}
+}
+
+// parseDigits parses up to max leading digits of s in base.
+func parseDigits(s string, base, max int) (int, int) {
+	digits := 0
+	for digits < max && digits < len(s) {
+		if _, err := strconv.ParseUint(s[digits:digits+1], base, 8); err != nil {
+			break
+		}
+		digits++
+	}
+	n, _ := strconv.ParseUint(s[:digits], base, 32)
+	return int(n), digits
+}
+
+// parseArgvWindows splits s like CommandLineToArgvW. The program name ends
+// at the first unquoted space or tab and has no escapes. In the following
+// arguments 2n backslashes before a double quote produce n backslashes and
+// the quote toggles quoting, 2n+1 backslashes produce n backslashes and a
+// literal quote, and "" inside quotes produces a literal quote. Other
+// backslashes are literal.
+func parseArgvWindows(s string) []string {
+	argv := []string{}
+	s = strings.TrimLeft(s, " \t")
+	if s == "" {
+		return argv
+	}
+
+	arg := []byte{}
+	inQuote := false
+	i := 0
+	for ; i < len(s) && (inQuote || (s[i] != ' ' && s[i] != '\t')); i++ {
+		if s[i] == '"' {
+			inQuote = !inQuote
+		} else {
+			arg = append(arg, s[i])
+		}
+	}
+	argv = append(argv, string(arg))
+
+	for {
+		for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+			i++
+		}
+		if i == len(s) {
+			return argv
+		}
+		arg = arg[:0]
+		inQuote = false
+		slashes := 0
+		for ; i < len(s); i++ {
+			c := s[i]
+			if c == '\\' {
+				slashes++
+				continue
+			}
+			if c == '"' {
+				arg = append(arg, strings.Repeat(`\`, slashes/2)...)
+				if slashes%2 == 1 {
+					arg = append(arg, c)
+				} else if inQuote && i+1 < len(s) && s[i+1] == '"' {
+					arg = append(arg, c)
+					i++
+					inQuote = false
+				} else {
+					inQuote = !inQuote
+				}
+				slashes = 0
+				continue
+			}
+			arg = append(arg, strings.Repeat(`\`, slashes)...)
+			slashes = 0
+			if !inQuote && (c == ' ' || c == '\t') {
+				break
+			}
+			arg = append(arg, c)
+		}
+		arg = append(arg, strings.Repeat(`\`, slashes)...)
+		argv = append(argv, string(arg))
+	}
+}
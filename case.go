@@ -0,0 +1,324 @@
+package str
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// commonInitialisms is golint's list of initialisms that Go identifiers
+// spell in upper case.
+var commonInitialisms = []string{
+	"ACL", "API", "ASCII", "CPU", "CSS", "DNS", "EOF", "GUID", "HTML", "HTTP",
+	"HTTPS", "ID", "IP", "JSON", "LHS", "QPS", "RAM", "RHS", "RPC", "SLA",
+	"SMTP", "SQL", "SSH", "TCP", "TLS", "TTL", "UDP", "UI", "UID", "UUID",
+	"URI", "URL", "UTF8", "VM", "XML", "XMPP", "XSRF", "XSS",
+}
+
+// CommonInitialisms returns golint's list of common initialisms, such as
+// "ID" and "HTTP", which are the acronyms used by Camelize, Classify,
+// Dasherize and Underscore.
+func CommonInitialisms() []string {
+	return append([]string{}, commonInitialisms...)
+}
+
+// CaseConverter converts between camel, Pascal, kebab and snake case like
+// Camelize, Classify, Dasherize and Underscore, but with its own set of
+// acronyms and locale. A CaseConverter is safe for concurrent use.
+type CaseConverter struct {
+	acronyms map[string]string
+	// maxWords is the most words in an acronym, such as 2 for "GraphQL".
+	maxWords int
+	casing   *casing
+}
+
+// NewCaseConverter returns a CaseConverter that spells acronyms as given
+// when camelizing, so "user_id" becomes "userID" if "ID" is an acronym.
+// Acronyms that Words splits, such as "GraphQL", are kept as one word. Use
+// CommonInitialisms to extend the default set, or no acronyms to disable
+// them.
+func NewCaseConverter(acronyms ...string) *CaseConverter {
+	return NewCaseConverterLocale("", acronyms...)
+}
+
+// NewCaseConverterLocale is like NewCaseConverter but splits words as
+// WordsLocale does and cases them by the rules of locale, so the Dutch
+// ("nl") "ijssel_meer" classifies to "IJsselMeer".
+func NewCaseConverterLocale(locale string, acronyms ...string) *CaseConverter {
+	c := &CaseConverter{acronyms: map[string]string{}, maxWords: 1, casing: casingFor(locale)}
+	for _, acronym := range acronyms {
+		c.acronyms[strings.ToLower(acronym)] = acronym
+		c.maxWords = max(c.maxWords, len(c.casing.words(acronym)))
+	}
+	return c
+}
+
+var defaultCaseConverter = NewCaseConverter(commonInitialisms...)
+
+// Camelize is like the Camelize function, using c's acronyms and locale.
+func (c *CaseConverter) Camelize(s string) string {
+	words, lead := c.words(s)
+	return c.camelize(words, lead)
+}
+
+// Classify is like the Classify function, using c's acronyms and locale.
+func (c *CaseConverter) Classify(s string) string {
+	words, _ := c.words(s)
+	return c.camelize(words, true)
+}
+
+// Convert is like ConvertCase, using c's acronyms and locale.
+func (c *CaseConverter) Convert(s string, to Case) string {
+	words, _ := c.words(s)
+	switch to {
+	case CaseCamel:
+		return c.camelize(words, false)
+	case CasePascal:
+		return c.camelize(words, true)
+	case CaseSnake:
+		return joinWords(words, "_", c.casing.lowerCase)
+	case CaseScreamingSnake:
+		return joinWords(words, "_", c.casing.upperCase)
+	case CaseKebab:
+		return joinWords(words, "-", c.casing.lowerCase)
+	case CaseTrain:
+		return joinWords(words, "-", c.title)
+	case CaseDot:
+		return joinWords(words, ".", c.casing.lowerCase)
+	case CasePath:
+		return joinWords(words, "/", c.casing.lowerCase)
+	case CaseTitle:
+		return joinWords(words, " ", c.title)
+	case CaseSentence:
+		for i, w := range words {
+			if i == 0 {
+				words[i] = c.title(w)
+			} else {
+				words[i] = c.spell(w, c.casing.lowerCase)
+			}
+		}
+		return strings.Join(words, " ")
+	case CaseFlat:
+		return joinWords(words, "", c.casing.lowerCase)
+	}
+	return s
+}
+
+// Dasherize is like the Dasherize function, keeping c's acronyms as one
+// word.
+func (c *CaseConverter) Dasherize(s string) string {
+	return c.join(s, "-")
+}
+
+// Underscore is like the Underscore function, keeping c's acronyms as one
+// word.
+func (c *CaseConverter) Underscore(s string) string {
+	return c.join(s, "_")
+}
+
+// words returns the caseWords of c's locale with upper case runs of
+// acronyms split, as in "XMLHTTP", and the words making up an acronym
+// merged, as in "GraphQL".
+func (c *CaseConverter) words(s string) ([]string, bool) {
+	words, lead := c.casing.caseWords(s)
+	split := []string{}
+	for _, w := range words {
+		if parts := c.splitAcronyms(w); parts != nil {
+			split = append(split, parts...)
+		} else {
+			split = append(split, w)
+		}
+	}
+	words = split
+	if c.maxWords < 2 {
+		return words, lead
+	}
+	merged := []string{}
+	for i := 0; i < len(words); {
+		n := 1
+		for k := min(c.maxWords, len(words)-i); k > 1; k-- {
+			if _, ok := c.acronyms[strings.ToLower(strings.Join(words[i:i+k], ""))]; ok {
+				n = k
+				break
+			}
+		}
+		merged = append(merged, strings.Join(words[i:i+n], ""))
+		i += n
+	}
+	return merged, lead
+}
+
+// splitAcronyms splits the upper case word w into acronyms, preferring the
+// longest first. It returns nil if w has lower case letters or is not made
+// of acronyms.
+func (c *CaseConverter) splitAcronyms(w string) []string {
+	if strings.IndexFunc(w, unicode.IsLower) >= 0 {
+		return nil
+	}
+	runes := []rune(w)
+	// parts[i] holds the split of runes[i:], or nil if there is none.
+	parts := make([][]string, len(runes)+1)
+	parts[len(runes)] = []string{}
+	for i := len(runes) - 1; i >= 0; i-- {
+		for j := len(runes); j > i; j-- {
+			if parts[j] == nil {
+				continue
+			}
+			if _, ok := c.acronyms[strings.ToLower(string(runes[i:j]))]; ok {
+				parts[i] = append([]string{string(runes[i:j])}, parts[j]...)
+				break
+			}
+		}
+	}
+	return parts[0]
+}
+
+func (c *CaseConverter) camelize(words []string, upperFirst bool) string {
+	for i, w := range words {
+		if i == 0 && !upperFirst {
+			words[i] = c.casing.lowerCase(w)
+		} else {
+			words[i] = c.spell(w, c.casing.titleWord)
+		}
+	}
+	return strings.Join(words, "")
+}
+
+// spell returns w spelled as the acronym it is, or else fn(w).
+func (c *CaseConverter) spell(w string, fn func(string) string) string {
+	if acronym, ok := c.acronyms[strings.ToLower(w)]; ok {
+		return acronym
+	}
+	return fn(w)
+}
+
+// title title cases w unless it is an acronym.
+func (c *CaseConverter) title(w string) string {
+	return c.spell(w, c.casing.titleWord)
+}
+
+// join joins the lower cased words of s with sep, after a leading sep if s
+// has a leading marker.
+func (c *CaseConverter) join(s, sep string) string {
+	words, lead := c.words(s)
+	s = joinWords(words, sep, c.casing.lowerCase)
+	if lead {
+		return sep + s
+	}
+	return s
+}
+
+// caseNames names the cases for the convertCase filter and Case.String.
+var caseNames = map[string]Case{
+	"camel":          CaseCamel,
+	"pascal":         CasePascal,
+	"snake":          CaseSnake,
+	"screamingSnake": CaseScreamingSnake,
+	"kebab":          CaseKebab,
+	"train":          CaseTrain,
+	"dot":            CaseDot,
+	"path":           CasePath,
+	"title":          CaseTitle,
+	"sentence":       CaseSentence,
+	"flat":           CaseFlat,
+}
+
+// detectCase implements DetectCase.
+func detectCase(s string) Case {
+	s = strings.TrimSpace(s)
+	if strings.IndexFunc(s, unicode.IsLower) < 0 && strings.IndexFunc(s, isUpperOrTitle) < 0 {
+		return CaseMixed
+	}
+	if strings.IndexFunc(s, unicode.IsSpace) >= 0 {
+		return detectSpacedCase(s)
+	}
+	sep := rune(0)
+	for _, r := range s {
+		switch {
+		case r == '_' || r == '-' || r == '.' || r == '/':
+			if sep != 0 && sep != r {
+				return CaseMixed
+			}
+			sep = r
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r):
+			return CaseMixed
+		}
+	}
+	hasUpper := strings.IndexFunc(s, isUpperOrTitle) >= 0
+	hasLower := strings.IndexFunc(s, unicode.IsLower) >= 0
+	switch {
+	case sep == 0 && !hasLower:
+		return CaseScreamingSnake
+	case sep == 0 && isCapitalized(s):
+		return CasePascal
+	case sep == 0 && hasUpper:
+		return CaseCamel
+	case sep == 0:
+		return CaseFlat
+	case sep == '_' && !hasUpper:
+		return CaseSnake
+	case sep == '_' && !hasLower:
+		return CaseScreamingSnake
+	case sep == '-' && !hasUpper:
+		return CaseKebab
+	case sep == '-' && hasLower:
+		for _, part := range strings.Split(s, "-") {
+			if part != "" && !isCapitalized(part) {
+				return CaseMixed
+			}
+		}
+		return CaseTrain
+	case sep == '.' && !hasUpper:
+		return CaseDot
+	case sep == '/' && !hasUpper:
+		return CasePath
+	}
+	return CaseMixed
+}
+
+// detectSpacedCase tells title case from sentence case. Acronyms and other
+// words with inner capitals, such as "NASA" and "iPhone", fit either, and
+// minor words such as "of" may be lower case in a title.
+func detectSpacedCase(s string) Case {
+	if strings.IndexFunc(s, unicode.IsLower) < 0 {
+		return CaseMixed
+	}
+	capitalized, acronym, lower := false, false, false
+	for i, w := range strings.Fields(s) {
+		w = strings.TrimFunc(w, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		switch {
+		case w == "":
+		case i == 0 && !isCapitalized(w):
+			return CaseMixed
+		case i == 0:
+		case hasInnerUpper(w):
+			// Acronyms such as "ID" are upper case in both title and
+			// sentence case; brand names such as "iPhone" in either.
+			acronym = acronym || strings.IndexFunc(w, unicode.IsLower) < 0
+		case isCapitalized(w):
+			capitalized = true
+		case !minorWords[TitleChicago][w] && !minorWords[TitleAP][w]:
+			lower = true
+		}
+	}
+	switch {
+	case capitalized && lower:
+		return CaseMixed
+	case capitalized || acronym && !lower:
+		return CaseTitle
+	}
+	return CaseSentence
+}
+
+// isCapitalized reports whether the first letter of s is upper or title
+// case.
+func isCapitalized(s string) bool {
+	i := strings.IndexFunc(s, unicode.IsLetter)
+	if i < 0 {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(s[i:])
+	return isUpperOrTitle(r)
+}
@@ -0,0 +1,238 @@
+// Command str applies the filters of package str to text from the command
+// line.
+//
+// Usage:
+//
+//	str [-l] filter [args...] [-- input...]
+//	str [-l] 'pipeline' [-- input...]
+//	str [-l] template [-data file] [-strict] [-- template...]
+//	str list
+//
+// A filter is any name printed by "str list", such as slugify, camelize or
+// between, followed by its arguments:
+//
+//	str between '<a>' '</a>' < page.html
+//
+// A single argument is parsed as a pipeline of "|" separated filters, as
+// accepted by str.ParsePipeline:
+//
+//	cat names | str -l 'clean | classify'
+//
+// Arguments after "--" are filtered and printed one per line. Otherwise str
+// filters its standard input, all at once or, with -l, line by line. In the
+// first case a trailing newline is removed from the input and added to the
+// output.
+//
+// The template command renders its input as a template, looking up
+// placeholders in the JSON object read from the -data file. With -strict, a
+// missing key is an error.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mgutz/str"
+)
+
+const usage = `usage: str [-l] filter [args...] [-- input...]
+       str [-l] 'pipeline' [-- input...]
+       str [-l] template [-data file] [-strict] [-- template...]
+       str list
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command line args and returns the exit status: 0 on
+// success, 1 if filtering failed and 2 if args are invalid.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("str", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+	lines := flags.Bool("l", false, "filter each line of the input separately")
+	if err := flags.Parse(args); err != nil {
+		return exitStatus(err)
+	}
+	args = flags.Args()
+	if len(args) == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	var inputs []string
+	hasInputs := false
+	for i, arg := range args {
+		if arg == "--" {
+			args, inputs, hasInputs = args[:i], args[i+1:], true
+			break
+		}
+	}
+
+	var filter func(string) (string, error)
+	var err error
+	switch args[0] {
+	case "list":
+		fmt.Fprintln(stdout, strings.Join(str.FilterNames(), "\n"))
+		return 0
+	case "template":
+		filter, err = templateFilter(args[1:], stderr)
+	default:
+		filter, err = pipelineFilter(args)
+	}
+	if err != nil {
+		if _, ok := err.(flagError); !ok {
+			fmt.Fprintf(stderr, "str: %v\n", err)
+		}
+		return exitStatus(err)
+	}
+
+	// A failed input is reported and produces no output.
+	status := 0
+	apply := func(s string) (string, bool) {
+		result, err := filter(s)
+		if err != nil {
+			fmt.Fprintf(stderr, "str: %v\n", err)
+			status = 1
+			return "", false
+		}
+		return result, true
+	}
+	switch {
+	case hasInputs:
+		for _, input := range inputs {
+			if result, ok := apply(input); ok {
+				fmt.Fprintln(stdout, result)
+			}
+		}
+	case *lines:
+		err = filterLines(stdout, stdin, apply)
+	default:
+		var b []byte
+		if b, err = io.ReadAll(stdin); err == nil {
+			input := strings.TrimSuffix(strings.TrimSuffix(string(b), "\n"), "\r")
+			if result, ok := apply(input); ok {
+				_, err = fmt.Fprintln(stdout, result)
+			}
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "str: %v\n", err)
+		return 1
+	}
+	return status
+}
+
+// filterLines is str.FilterLines for a filter that can fail: it preserves
+// line endings, and writes nothing for a line that failed.
+func filterLines(w io.Writer, r io.Reader, apply func(string) (string, bool)) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, str.DefaultMaxRecord)
+	sc.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			return i + 1, data[:i+1], nil
+		}
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	})
+	bw := bufio.NewWriter(w)
+	for sc.Scan() {
+		line := sc.Text()
+		body := strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		if result, ok := apply(body); ok {
+			bw.WriteString(result + line[len(body):])
+		}
+	}
+	err := sc.Err()
+	if ferr := bw.Flush(); err == nil {
+		err = ferr
+	}
+	return err
+}
+
+// flagError is an error already reported by package flag.
+type flagError struct {
+	err error
+}
+
+func (e flagError) Error() string {
+	return e.err.Error()
+}
+
+// exitStatus returns the exit status for an invalid command line.
+func exitStatus(err error) int {
+	if err == flag.ErrHelp || err == (flagError{flag.ErrHelp}) {
+		return 0
+	}
+	return 2
+}
+
+// pipelineFilter parses args as a single pipeline or as a filter name
+// followed by its arguments.
+func pipelineFilter(args []string) (func(string) (string, error), error) {
+	spec := args[0]
+	for _, arg := range args[1:] {
+		spec += " " + strconv.Quote(arg)
+	}
+	f, err := str.ParsePipeline(spec)
+	if err != nil {
+		return nil, err
+	}
+	return func(s string) (string, error) {
+		return f(s), nil
+	}, nil
+}
+
+// templateFilter parses the flags of the template command and returns a
+// filter rendering its input with the data they name.
+func templateFilter(args []string, stderr io.Writer) (func(string) (string, error), error) {
+	flags := flag.NewFlagSet("str template", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	dataFile := flags.String("data", "", "read placeholder values from JSON `file`")
+	strict := flags.Bool("strict", false, "fail on placeholders missing from the data")
+	if err := flags.Parse(args); err != nil {
+		return nil, flagError{err}
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("template: unexpected argument %q", flags.Arg(0))
+	}
+
+	var data interface{} = map[string]interface{}{}
+	if *dataFile != "" {
+		f, err := os.Open(*dataFile)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		d := json.NewDecoder(f)
+		d.UseNumber()
+		if err := d.Decode(&data); err != nil {
+			return nil, fmt.Errorf("%s: %v", *dataFile, err)
+		}
+	}
+
+	opts := str.TemplateOptions{}
+	if *strict {
+		opts.Missing = str.MissingKeyError
+	}
+	return func(s string) (string, error) {
+		t, err := str.CompileTemplate(s, opts)
+		if err != nil {
+			return "", err
+		}
+		return t.Render(data)
+	}, nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+func Example() {
+	run([]string{"slugify"}, strings.NewReader("  Hello World  \n"), os.Stdout, os.Stdout)
+	run([]string{"-l", "clean | classify"}, strings.NewReader("john smith\n  mary-jane\n"), os.Stdout, os.Stdout)
+	run([]string{"between", "<a>", "</a>", "--", "<a>x</a>", "y<a>z</a>"}, nil, os.Stdout, os.Stdout)
+	run([]string{"-l", "template"}, strings.NewReader("{{a | left x}}\nok\n"), os.Stdout, os.Stdout)
+	status := run([]string{"shout"}, nil, os.Stdout, os.Stdout)
+	fmt.Println("status", status)
+	// Output:
+	// hello-world
+	// JohnSmith
+	// MaryJane
+	// x
+	// z
+	// str: template:1:12: left expects an integer, got "x"
+	// ok
+	// str: pipeline:0: unknown filter "shout"
+	// status 2
+}
+
+func Example_template() {
+	f, err := os.CreateTemp("", "vals*.json")
+	if err != nil {
+		panic(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString(`{"name": "World", "n": 42}`)
+	f.Close()
+
+	run([]string{"template", "--data", f.Name()}, strings.NewReader("Hi {{name}} {{n}} {{x}}\n"), os.Stdout, os.Stdout)
+	status := run([]string{"template", "--data", f.Name(), "--strict", "--", "Hi {{x}}"}, nil, os.Stdout, os.Stdout)
+	fmt.Println("status", status)
+	// Output:
+	// Hi World 42 {{x}}
+	// str: template:1:4: no value for key "x"
+	// status 1
+}
@@ -0,0 +1,444 @@
+package str
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// filterFactory builds a filter from the arguments written after its name.
+type filterFactory func(args []string) (func(string) string, error)
+
+// filterRegistry maps filter names to factories.
+type filterRegistry struct {
+	sync.RWMutex
+	factories map[string]filterFactory
+}
+
+var defaultFilters = &filterRegistry{factories: map[string]filterFactory{
+	"between":            filterSS(BetweenF),
+	"camelize":           filter0(Camelize),
+	"capitalize":         filter0(Capitalize),
+	"capitalizeLocale":   filterS(CapitalizeLocaleF),
+	"charAt":             filterI(CharAtF),
+	"charAtGraphemes":    filterI(CharAtGraphemesF),
+	"charAtRunes":        filterI(CharAtRunesF),
+	"chompLeft":          filterS(ChompLeftF),
+	"chompRight":         filterS(ChompRightF),
+	"classify":           filter0(Classify),
+	"clean":              filter0(Clean),
+	"convertCase":        filterConvertCase,
+	"dasherize":          filter0(Dasherize),
+	"decodeHTMLEntities": filter0(html.UnescapeString),
+	"ensurePrefix":       filterS(EnsurePrefixF),
+	"ensureSuffix":       filterS(EnsureSuffixF),
+	"escapeHTML":         filter0(html.EscapeString),
+	"humanize":           filter0(Humanize),
+	"left":               filterI(LeftF),
+	"leftGraphemes":      filterI(LeftGraphemesF),
+	"leftRunes":          filterI(LeftRunesF),
+	"lower":              filter0(strings.ToLower),
+	"matchAll":           filterPattern(MatchAllF),
+	"matchGroup":         filterMatchGroup,
+	"pad":                filterSI(PadF),
+	"padLeft":            filterSI(PadLeftF),
+	"padLeftWidth":       filterSI(PadLeftWidthF),
+	"padRight":           filterSI(PadRightF),
+	"padRightWidth":      filterSI(PadRightWidthF),
+	"padWidth":           filterSI(PadWidthF),
+	"replace":            filterReplace,
+	"replacePattern":     filterPattern(ReplacePatternF),
+	"reverse":            filter0(Reverse),
+	"reverseGraphemes":   filter0(ReverseGraphemes),
+	"right":              filterI(RightF),
+	"rightGraphemes":     filterI(RightGraphemesF),
+	"rightRunes":         filterI(RightRunesF),
+	"slice":              filterII(SliceF),
+	"sliceGraphemes":     filterII(SliceGraphemesF),
+	"sliceRunes":         filterII(SliceRunesF),
+	"slugify":            filter0(Slugify),
+	"splitPattern":       filterPattern(SplitPatternF),
+	"stripPunctuation":   filter0(StripPunctuation),
+	"stripTags":          filterStripTags,
+	"substr":             filterII(SubstrF),
+	"substrGraphemes":    filterII(SubstrGraphemesF),
+	"substrRunes":        filterII(SubstrRunesF),
+	"titleLocale":        filterS(TitleLocaleF),
+	"titleize":           filterTitleize,
+	"toLowerLocale":      filterS(ToLowerLocaleF),
+	"toUpperLocale":      filterS(ToUpperLocaleF),
+	"trim":               filter0(strings.TrimSpace),
+	"truncate":           filterTruncate(TruncateF),
+	"truncateMiddle":     filterTruncate(TruncateMiddleF),
+	"truncateStart":      filterTruncate(TruncateStartF),
+	"underscore":         filter0(Underscore),
+	"unescapeHTML":       filter0(html.UnescapeString),
+	"upper":              filter0(strings.ToUpper),
+	"wrapHTML":           filterWrapHTML,
+}}
+
+func (r *filterRegistry) register(name string, factory filterFactory) {
+	r.Lock()
+	defer r.Unlock()
+	r.factories[name] = factory
+}
+
+func (r *filterRegistry) clone() *filterRegistry {
+	r.RLock()
+	defer r.RUnlock()
+	factories := map[string]filterFactory{}
+	for name, factory := range r.factories {
+		factories[name] = factory
+	}
+	return &filterRegistry{factories: factories}
+}
+
+func (r *filterRegistry) lookup(name string) (filterFactory, bool) {
+	r.RLock()
+	defer r.RUnlock()
+	factory, ok := r.factories[name]
+	return factory, ok
+}
+
+// RegisterFilter makes fn available by name to template placeholders, for
+// example {{title | shout}}, and to ParsePipeline. Registering an existing name replaces it.
+// Templaters created by NewTemplater afterwards inherit the filter.
+func RegisterFilter(name string, fn func(string) string) {
+	defaultFilters.register(name, filter0(fn))
+}
+
+// RegisterFilterFactory makes a filter that takes arguments available by
+// name. factory receives the arguments following the name, unquoted, and
+// returns an error if they are invalid.
+func RegisterFilterFactory(name string, factory func(args []string) (func(string) string, error)) {
+	defaultFilters.register(name, factory)
+}
+
+// FilterNames returns the sorted names of the filters available to
+// template placeholders and ParsePipeline.
+func FilterNames() []string {
+	defaultFilters.RLock()
+	defer defaultFilters.RUnlock()
+	names := []string{}
+	for name := range defaultFilters.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func filter0(fn func(string) string) filterFactory {
+	return func(args []string) (func(string) string, error) {
+		if err := checkArity(args, 0, 0); err != nil {
+			return nil, err
+		}
+		return fn, nil
+	}
+}
+
+func filterS(fn func(string) func(string) string) filterFactory {
+	return func(args []string) (func(string) string, error) {
+		if err := checkArity(args, 1, 1); err != nil {
+			return nil, err
+		}
+		return fn(args[0]), nil
+	}
+}
+
+func filterSS(fn func(string, string) func(string) string) filterFactory {
+	return func(args []string) (func(string) string, error) {
+		if err := checkArity(args, 2, 2); err != nil {
+			return nil, err
+		}
+		return fn(args[0], args[1]), nil
+	}
+}
+
+func filterI(fn func(int) func(string) string) filterFactory {
+	return func(args []string) (func(string) string, error) {
+		if err := checkArity(args, 1, 1); err != nil {
+			return nil, err
+		}
+		n, err := filterInt(args, 0)
+		if err != nil {
+			return nil, err
+		}
+		return fn(n), nil
+	}
+}
+
+func filterII(fn func(int, int) func(string) string) filterFactory {
+	return func(args []string) (func(string) string, error) {
+		if err := checkArity(args, 2, 2); err != nil {
+			return nil, err
+		}
+		a, err := filterInt(args, 0)
+		if err != nil {
+			return nil, err
+		}
+		b, err := filterInt(args, 1)
+		if err != nil {
+			return nil, err
+		}
+		return fn(a, b), nil
+	}
+}
+
+func filterSI(fn func(string, int) func(string) string) filterFactory {
+	return func(args []string) (func(string) string, error) {
+		if err := checkArity(args, 2, 2); err != nil {
+			return nil, err
+		}
+		n, err := filterInt(args, 1)
+		if err != nil {
+			return nil, err
+		}
+		return fn(args[0], n), nil
+	}
+}
+
+// filterConvertCase takes a case name such as snake or screamingSnake.
+func filterConvertCase(args []string) (func(string) string, error) {
+	if err := checkArity(args, 1, 1); err != nil {
+		return nil, err
+	}
+	to, ok := caseNames[args[0]]
+	if !ok {
+		return nil, &argError{0, fmt.Sprintf("unknown case %q", args[0])}
+	}
+	return ConvertCaseF(to), nil
+}
+
+func filterMatchGroup(args []string) (func(string) string, error) {
+	if err := checkArity(args, 2, 2); err != nil {
+		return nil, err
+	}
+	r, err := compilePattern(args[0])
+	if err != nil {
+		return nil, &argError{0, err.Error()}
+	}
+	// An empty match has every group key of a real one.
+	if _, ok := patternGroups(r, "", make([]int, 2*r.NumSubexp()+2))[args[1]]; !ok {
+		return nil, &argError{1, fmt.Sprintf("pattern has no group %q", args[1])}
+	}
+	return MatchGroupF(args[0], args[1]), nil
+}
+
+func filterReplace(args []string) (func(string) string, error) {
+	if err := checkArity(args, 2, 3); err != nil {
+		return nil, err
+	}
+	n := -1
+	if len(args) == 3 {
+		var err error
+		if n, err = filterInt(args, 2); err != nil {
+			return nil, err
+		}
+	}
+	return ReplaceF(args[0], args[1], n), nil
+}
+
+// filterPattern takes a pattern, which is checked up front, and a string.
+func filterPattern(fn func(pattern, s string) func(string) string) filterFactory {
+	return func(args []string) (func(string) string, error) {
+		if err := checkArity(args, 2, 2); err != nil {
+			return nil, err
+		}
+		if _, err := compilePattern(args[0]); err != nil {
+			return nil, &argError{0, err.Error()}
+		}
+		return fn(args[0], args[1]), nil
+	}
+}
+
+func filterStripTags(args []string) (func(string) string, error) {
+	return func(s string) string {
+		return StripTags(s, args...)
+	}, nil
+}
+
+// filterTitleize takes an optional style name: simple, ap, chicago or apa.
+func filterTitleize(args []string) (func(string) string, error) {
+	if err := checkArity(args, 0, 1); err != nil {
+		return nil, err
+	}
+	style := TitleSimple
+	if len(args) == 1 {
+		var ok bool
+		if style, ok = titleStyles[strings.ToLower(args[0])]; !ok {
+			return nil, &argError{0, fmt.Sprintf("unknown title style %q", args[0])}
+		}
+	}
+	return TitleizeF(style), nil
+}
+
+func filterTruncate(fn func(int, string) func(string) string) filterFactory {
+	return func(args []string) (func(string) string, error) {
+		if err := checkArity(args, 1, 2); err != nil {
+			return nil, err
+		}
+		n, err := filterInt(args, 0)
+		if err != nil {
+			return nil, err
+		}
+		more := ""
+		if len(args) == 2 {
+			more = args[1]
+		}
+		return fn(n, more), nil
+	}
+}
+
+// filterWrapHTML takes a tag followed by attribute name and value pairs.
+func filterWrapHTML(args []string) (func(string) string, error) {
+	if len(args) == 0 || len(args)%2 == 0 {
+		return nil, fmt.Errorf("expects a tag and attribute name and value pairs, got %d arguments", len(args))
+	}
+	attrs := map[string]string{}
+	for i := 1; i < len(args); i += 2 {
+		attrs[args[i]] = args[i+1]
+	}
+	return WrapHTMLF(args[0], attrs), nil
+}
+
+func checkArity(args []string, min, max int) error {
+	switch {
+	case len(args) >= min && len(args) <= max:
+		return nil
+	case min == max:
+		return fmt.Errorf("expects %d arguments, got %d", min, len(args))
+	}
+	return fmt.Errorf("expects %d to %d arguments, got %d", min, max, len(args))
+}
+
+// filterInt parses args[i] as an integer. A failure is an *argError so the
+// error can point at the argument rather than the filter name.
+func filterInt(args []string, i int) (int, error) {
+	n, err := strconv.Atoi(args[i])
+	if err != nil {
+		return 0, &argError{i, fmt.Sprintf("expects an integer, got %q", args[i])}
+	}
+	return n, nil
+}
+
+// argError is a filter factory error caused by the argument at index.
+type argError struct {
+	index int
+	msg   string
+}
+
+func (e *argError) Error() string {
+	return e.msg
+}
+
+// syntaxError is a filter pipeline error at byte offset in the source.
+type syntaxError struct {
+	offset int
+	msg    string
+}
+
+func (e *syntaxError) Error() string {
+	return e.msg
+}
+
+// pipelineToken is a word or quoted string in a filter pipeline.
+type pipelineToken struct {
+	text   string
+	offset int
+	pipe   bool
+}
+
+// tokenizePipeline splits s into words, unquoted strings and "|" separators.
+// Double quoted strings accept Go escapes; single quoted strings are raw.
+func tokenizePipeline(s string) ([]pipelineToken, error) {
+	tokens := []pipelineToken{}
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '|':
+			tokens = append(tokens, pipelineToken{text: "|", offset: i, pipe: true})
+			i += size
+		case r == '"' || r == '\'':
+			end := i + 1
+			for end < len(s) && s[end] != byte(r) {
+				if r == '"' && s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(s) {
+				return nil, &syntaxError{i, "unterminated quoted string"}
+			}
+			text := s[i+1 : end]
+			if r == '"' {
+				var err error
+				if text, err = strconv.Unquote(s[i : end+1]); err != nil {
+					return nil, &syntaxError{i, "invalid quoted string " + s[i:end+1]}
+				}
+			}
+			tokens = append(tokens, pipelineToken{text: text, offset: i})
+			i = end + 1
+		default:
+			end := i
+			for end < len(s) {
+				r, size := utf8.DecodeRuneInString(s[end:])
+				if unicode.IsSpace(r) || r == '|' || r == '"' || r == '\'' {
+					break
+				}
+				end += size
+			}
+			tokens = append(tokens, pipelineToken{text: s[i:end], offset: i})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+// compilePipeline builds the filters of the "|" separated stages in tokens.
+// Each stage is a filter name followed by its arguments. end is the offset
+// reported when the last stage is missing.
+func (r *filterRegistry) compilePipeline(tokens []pipelineToken, end int) ([]func(string) string, error) {
+	filters := []func(string) string{}
+	var stage []pipelineToken
+	for i := 0; i <= len(tokens); i++ {
+		if i < len(tokens) && !tokens[i].pipe {
+			stage = append(stage, tokens[i])
+			continue
+		}
+		if len(stage) == 0 {
+			offset := end
+			if i < len(tokens) {
+				offset = tokens[i].offset
+			}
+			return nil, &syntaxError{offset, "missing filter name"}
+		}
+
+		name := stage[0]
+		factory, ok := r.lookup(name.text)
+		if !ok {
+			return nil, &syntaxError{name.offset, fmt.Sprintf("unknown filter %q", name.text)}
+		}
+		args := []string{}
+		for _, token := range stage[1:] {
+			args = append(args, token.text)
+		}
+		filter, err := factory(args)
+		if err != nil {
+			offset := name.offset
+			if aerr, ok := err.(*argError); ok && aerr.index < len(args) {
+				offset = stage[1+aerr.index].offset
+			}
+			return nil, &syntaxError{offset, fmt.Sprintf("%s %v", name.text, err)}
+		}
+		filters = append(filters, filter)
+		stage = nil
+	}
+	return filters, nil
+}
@@ -1,36 +1,24 @@
 package str
 
 import (
-	"fmt"
 	"html"
 	//"log"
 	"regexp"
 	"strings"
+	"unicode"
 )
 
 // Verbose flag enables console output for those functions that have
 // counterparts in Go's excellent stadard packages.
+//
+// Deprecated: Verbose writes to standard output. Use SetLogger instead.
 var Verbose = false
-var templateOpen = "{{"
-var templateClose = "}}"
 
 var beginEndSpacesRe = regexp.MustCompile("^\\s+|\\s+$")
-var camelizeRe = regexp.MustCompile(`(\-|_|\s)+(.)?`)
-var camelizeRe2 = regexp.MustCompile(`(\-|_|\s)+`)
-var capitalsRe = regexp.MustCompile("([A-Z])")
-var dashSpaceRe = regexp.MustCompile(`[-\s]+`)
-var dashesRe = regexp.MustCompile("-+")
-var isAlphaNumericRe = regexp.MustCompile(`[^0-9a-z\xC0-\xFF]`)
-var isAlphaRe = regexp.MustCompile(`[^a-z\xC0-\xFF]`)
 var nWhitespaceRe = regexp.MustCompile(`\s+`)
 var notDigitsRe = regexp.MustCompile(`[^0-9]`)
-var slugifyRe = regexp.MustCompile(`[^\w\s\-]`)
-var spaceUnderscoreRe = regexp.MustCompile("[_\\s]+")
 var spacesRe = regexp.MustCompile("[\\s\\xA0]+")
 var stripPuncRe = regexp.MustCompile(`[^\w\s]|_`)
-var templateRe = regexp.MustCompile(`([\-\[\]()*\s])`)
-var templateRe2 = regexp.MustCompile(`\$`)
-var underscoreRe = regexp.MustCompile(`([a-z\d])([A-Z]+)`)
 var whitespaceRe = regexp.MustCompile(`^[\s\xa0]*$`)
 
 func min(a, b int) int {
@@ -72,18 +60,30 @@ func BetweenF(left, right string) func(string) string {
 	}
 }
 
-// Camelize return new string which removes any underscores or dashes and convert a string into camel casing.
+// Camelize joins the Words of s in camel case, as in "dataRate". The first
+// word is capitalized too if s starts with "-", "_" or an upper case letter,
+// so Camelize("-moz-transform") is "MozTransform". Common initialisms are
+// upper cased, as in "userID"; see CaseConverter.
 func Camelize(s string) string {
-	return camelizeRe.ReplaceAllStringFunc(s, func(val string) string {
-		val = strings.ToUpper(val)
-		val = camelizeRe2.ReplaceAllString(val, "")
-		return val
-	})
+	return defaultCaseConverter.Camelize(s)
 }
 
-// Capitalize uppercases the first char of s and lowercases the rest.
+// Capitalize title cases the first character of s and lowercases the rest.
 func Capitalize(s string) string {
-	return strings.ToUpper(s[0:1]) + strings.ToLower(s[1:])
+	return titleWord(s)
+}
+
+// CapitalizeLocale is like Capitalize but applies the casing rules of
+// locale, such as "tr" or "lt-LT". See ToUpperLocale.
+func CapitalizeLocale(s, locale string) string {
+	return casingFor(locale).titleWord(s)
+}
+
+// CapitalizeLocaleF is the filter form of CapitalizeLocale.
+func CapitalizeLocaleF(locale string) func(string) string {
+	return func(s string) string {
+		return CapitalizeLocale(s, locale)
+	}
 }
 
 // CharAt returns a string from the character at the specified position.
@@ -103,6 +103,31 @@ func CharAtF(index int) func(string) string {
 	}
 }
 
+// CharAtGraphemes returns the user-perceived character (grapheme cluster) at
+// the specified position.
+func CharAtGraphemes(s string, index int) string {
+	return substrBounds(s, graphemeBounds(s), index, 1)
+}
+
+// CharAtGraphemesF is the filter form of CharAtGraphemes.
+func CharAtGraphemesF(index int) func(string) string {
+	return func(s string) string {
+		return CharAtGraphemes(s, index)
+	}
+}
+
+// CharAtRunes returns the rune at the specified position as a string.
+func CharAtRunes(s string, index int) string {
+	return substrBounds(s, runeBounds(s), index, 1)
+}
+
+// CharAtRunesF is the filter form of CharAtRunes.
+func CharAtRunesF(index int) func(string) string {
+	return func(s string) string {
+		return CharAtRunes(s, index)
+	}
+}
+
 // ChompLeft removes prefix at the start of a string.
 func ChompLeft(s, prefix string) string {
 	if strings.HasPrefix(s, prefix) {
@@ -133,9 +158,10 @@ func ChompRightF(suffix string) func(string) string {
 	}
 }
 
-// Classify returns a camelized string with the first letter upper cased.
+// Classify joins the Words of s in Pascal case, as in "DataRate". Common
+// initialisms are upper cased, as in "HTTPServer"; see CaseConverter.
 func Classify(s string) string {
-	return Camelize("-" + s)
+	return defaultCaseConverter.Classify(s)
 }
 
 // ClassifyF is the filter form of Classify.
@@ -152,30 +178,90 @@ func Clean(s string) string {
 	return s
 }
 
-// Dasherize  converts a camel cased string into a string delimited by dashes.
+// Case is an identifier or text case, as reported by DetectCase.
+type Case int
+
+// Cases.
+const (
+	// CaseMixed is text in none of the other cases.
+	CaseMixed Case = iota
+	// CaseCamel is "dataRate".
+	CaseCamel
+	// CasePascal is "DataRate".
+	CasePascal
+	// CaseSnake is "data_rate".
+	CaseSnake
+	// CaseScreamingSnake is "DATA_RATE".
+	CaseScreamingSnake
+	// CaseKebab is "data-rate".
+	CaseKebab
+	// CaseTrain is "Data-Rate".
+	CaseTrain
+	// CaseDot is "data.rate".
+	CaseDot
+	// CasePath is "data/rate".
+	CasePath
+	// CaseTitle is "Data Rate".
+	CaseTitle
+	// CaseSentence is "Data rate".
+	CaseSentence
+	// CaseFlat is "datarate".
+	CaseFlat
+)
+
+// String returns the name of c as accepted by the convertCase filter, such
+// as "screamingSnake", or "mixed" for CaseMixed.
+func (c Case) String() string {
+	for name, named := range caseNames {
+		if named == c {
+			return name
+		}
+	}
+	return "mixed"
+}
+
+// ConvertCase joins the Words of s in the case to. Common initialisms keep
+// their spelling in the capitalized cases, as in "userID" and "User ID"; see
+// CaseConverter. Converting to CaseMixed returns s.
+func ConvertCase(s string, to Case) string {
+	return defaultCaseConverter.Convert(s, to)
+}
+
+// ConvertCaseF is the filter form of ConvertCase.
+func ConvertCaseF(to Case) func(string) string {
+	return func(s string) string {
+		return ConvertCase(s, to)
+	}
+}
+
+// Dasherize joins the lower cased Words of s with dashes, as in
+// "data-rate". A leading "-", "_" or upper case letter in s becomes a
+// leading dash, so Dasherize("CarSpeed") is "-car-speed".
 func Dasherize(s string) string {
-	s = strings.TrimSpace(s)
-	s = spaceUnderscoreRe.ReplaceAllString(s, "-")
-	s = capitalsRe.ReplaceAllString(s, "-$1")
-	s = dashesRe.ReplaceAllString(s, "-")
-	s = strings.ToLower(s)
-	return s
+	return defaultCaseConverter.Dasherize(s)
+}
+
+// DetectCase reports the case of s, ignoring surrounding whitespace. A
+// single word is flat case if lower case, Pascal case if capitalized and
+// screaming snake case if upper case. Title case may have lower case minor
+// words such as "of", and both title and sentence case may have acronyms.
+// Text whose words after the first are only acronyms and minor words, such
+// as "User ID", is both; DetectCase reports it as CaseTitle. Text with no
+// cased letters, or mixing cases or separators, is CaseMixed.
+func DetectCase(s string) Case {
+	return detectCase(s)
 }
 
 // EscapeHTML is alias for html.EscapeString.
 func EscapeHTML(s string) string {
-	if Verbose {
-		fmt.Println("Use html.EscapeString instead of EscapeHTML")
-	}
+	deprecated("EscapeHTML", "html.EscapeString")
 	return html.EscapeString(s)
 }
 
 // DecodeHTMLEntities decodes HTML entities into their proper string representation.
 // DecodeHTMLEntities is an alias for html.UnescapeString
 func DecodeHTMLEntities(s string) string {
-	if Verbose {
-		fmt.Println("Use html.UnescapeString instead of DecodeHTMLEntities")
-	}
+	deprecated("DecodeHTMLEntities", "html.UnescapeString")
 	return html.UnescapeString(s)
 }
 
@@ -209,18 +295,29 @@ func EnsureSuffixF(suffix string) func(string) string {
 	}
 }
 
-// Humanize transforms s into a human friendly form.
+// Graphemes splits s into user-perceived characters (extended grapheme
+// clusters), keeping combining marks, emoji ZWJ sequences and flags intact.
+func Graphemes(s string) []string {
+	bounds := graphemeBounds(s)
+	result := []string{}
+	for i := 1; i < len(bounds); i++ {
+		result = append(result, s[bounds[i-1]:bounds[i]])
+	}
+	return result
+}
+
+// Humanize joins the lower cased Words of s with spaces and capitalizes the
+// result, as in "Data rate". A trailing "id" word is dropped, so
+// Humanize("author_id") is "Author".
 func Humanize(s string) string {
-	if s == "" {
-		return s
+	words := Words(s)
+	if len(words) > 1 && strings.EqualFold(words[len(words)-1], "id") {
+		words = words[:len(words)-1]
 	}
-	s = Underscore(s)
-	var humanizeRe = regexp.MustCompile(`_id$`)
-	s = humanizeRe.ReplaceAllString(s, "")
-	s = strings.Replace(s, "_", " ", -1)
-	s = strings.TrimSpace(s)
-	s = Capitalize(s)
-	return s
+	if len(words) == 0 {
+		return ""
+	}
+	return titleWord(joinWords(words, " ", lowerCase))
 }
 
 // Iif is short for immediate if. If condition is true return truthy else falsey.
@@ -253,19 +350,24 @@ func IndexOf(s string, needle string, start int) int {
 	return start + pos
 }
 
-// IsAlpha returns true if a string contains only letters from ASCII (a-z,A-Z). Other letters from other languages are not supported.
+// IsAlpha returns true if a string contains only letters, in any script.
 func IsAlpha(s string) bool {
-	return !isAlphaRe.MatchString(strings.ToLower(s))
+	return strings.IndexFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsMark(r)
+	}) < 0
 }
 
-// IsAlphaNumeric returns true if a string contains letters and digits.
+// IsAlphaNumeric returns true if a string contains only letters and digits,
+// in any script.
 func IsAlphaNumeric(s string) bool {
-	return !isAlphaNumericRe.MatchString(strings.ToLower(s))
+	return strings.IndexFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsMark(r) && !unicode.IsDigit(r)
+	}) < 0
 }
 
 // IsLower returns true if s comprised of all lower case characters.
 func IsLower(s string) bool {
-	return IsAlpha(s) && s == strings.ToLower(s)
+	return IsAlpha(s) && strings.IndexFunc(s, isUpperOrTitle) < 0
 }
 
 // IsNumeric returns true if a string contains only digits from 0-9. Other digits not in Latin (such as Arabic) are not currently supported.
@@ -275,7 +377,9 @@ func IsNumeric(s string) bool {
 
 // IsUpper returns true if s contains all upper case chracters.
 func IsUpper(s string) bool {
-	return IsAlpha(s) && s == strings.ToUpper(s)
+	return IsAlpha(s) && strings.IndexFunc(s, func(r rune) bool {
+		return unicode.IsLower(r) || unicode.IsTitle(r)
+	}) < 0
 }
 
 // IsEmpty returns true if the string is solely composed of whitespace.
@@ -301,6 +405,36 @@ func LeftF(n int) func(string) string {
 	}
 }
 
+// LeftGraphemes returns the left substring of n grapheme clusters.
+func LeftGraphemes(s string, n int) string {
+	if n < 0 {
+		return RightGraphemes(s, -n)
+	}
+	return SubstrGraphemes(s, 0, n)
+}
+
+// LeftGraphemesF is the filter form of LeftGraphemes.
+func LeftGraphemesF(n int) func(string) string {
+	return func(s string) string {
+		return LeftGraphemes(s, n)
+	}
+}
+
+// LeftRunes returns the left substring of n runes.
+func LeftRunes(s string, n int) string {
+	if n < 0 {
+		return RightRunes(s, -n)
+	}
+	return SubstrRunes(s, 0, n)
+}
+
+// LeftRunesF is the filter form of LeftRunes.
+func LeftRunesF(n int) func(string) string {
+	return func(s string) string {
+		return LeftRunes(s, n)
+	}
+}
+
 // LeftOf returns the substring left of needle.
 func LeftOf(s string, needle string) string {
 	return Between(s, "", needle)
@@ -330,8 +464,73 @@ func Map(arr []string, iterator func(string) string) []string {
 	return r
 }
 
-// Match returns true if patterns matches the string
+// Match returns true if patterns matches the string. Match panics if pattern
+// is invalid; use MatchE for patterns from untrusted input.
 func Match(s, pattern string) bool {
-	r := regexp.MustCompile(pattern)
+	r := mustCompilePattern(pattern)
 	return r.MatchString(s)
 }
+
+// MatchAll returns every match of pattern in s. It panics if pattern is
+// invalid.
+func MatchAll(s, pattern string) []PatternMatch {
+	r := mustCompilePattern(pattern)
+	matches := []PatternMatch{}
+	for _, loc := range r.FindAllStringSubmatchIndex(s, -1) {
+		matches = append(matches, PatternMatch{
+			Text:   s[loc[0]:loc[1]],
+			Start:  loc[0],
+			End:    loc[1],
+			Groups: patternGroups(r, s, loc),
+		})
+	}
+	return matches
+}
+
+// MatchAllF is the filter form of MatchAll. It joins the text of the
+// matches with sep.
+func MatchAllF(pattern, sep string) func(string) string {
+	return func(s string) string {
+		texts := []string{}
+		for _, m := range MatchAll(s, pattern) {
+			texts = append(texts, m.Text)
+		}
+		return strings.Join(texts, sep)
+	}
+}
+
+// MatchE is like Match but returns an error if pattern is invalid.
+func MatchE(s, pattern string) (bool, error) {
+	r, err := compilePattern(pattern)
+	if err != nil {
+		return false, err
+	}
+	return r.MatchString(s), nil
+}
+
+// MatchGroup returns the capture group of the first match of pattern in s
+// named or numbered group, as keyed by MatchGroups, or "" if there is no
+// match. MatchGroup panics if pattern is invalid.
+func MatchGroup(s, pattern, group string) string {
+	return MatchGroups(s, pattern)[group]
+}
+
+// MatchGroupF is the filter form of MatchGroup.
+func MatchGroupF(pattern, group string) func(string) string {
+	return func(s string) string {
+		return MatchGroup(s, pattern, group)
+	}
+}
+
+// MatchGroups returns the capture groups of the first match of pattern in
+// s, or nil if there is none. Groups are keyed by number, "0" being the
+// whole match, and named groups also by name. A group that did not take part
+// in the match is "". MatchGroups panics if pattern is invalid.
+func MatchGroups(s, pattern string) map[string]string {
+	r := mustCompilePattern(pattern)
+	loc := r.FindStringSubmatchIndex(s)
+	if loc == nil {
+		return nil
+	}
+	return patternGroups(r, s, loc)
+}
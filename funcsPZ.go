@@ -1,14 +1,12 @@
 package str
 
 import (
-	"fmt"
 	"html"
 	//"log"
 	"math"
-	"regexp"
-	"runtime"
 	"strconv"
 	"strings"
+	"unicode"
 	"unicode/utf8"
 )
 
@@ -64,12 +62,100 @@ func PadRightF(c string, n int) func(string) string {
 	}
 }
 
+// PadWidth pads s on both sides with c until it is n terminal columns wide.
+// Widths are measured as by Width and c is repeated or truncated to land
+// exactly on column n.
+func PadWidth(s, c string, n int) string {
+	return PadWidthWith(s, c, n, WidthOptions{})
+}
+
+// PadWidthF is the filter form of PadWidth.
+func PadWidthF(c string, n int) func(string) string {
+	return func(s string) string {
+		return PadWidth(s, c, n)
+	}
+}
+
+// PadWidthWith is like PadWidth, measuring widths as WidthWith does with
+// opts.
+func PadWidthWith(s, c string, n int, opts WidthOptions) string {
+	W := WidthWith(s, opts)
+	if W >= n {
+		return s
+	}
+	n -= W
+	return repeatWidth(c, (n+1)/2, opts) + s + repeatWidth(c, n/2, opts)
+}
+
+// PadWidthWithF is the filter form of PadWidthWith.
+func PadWidthWithF(c string, n int, opts WidthOptions) func(string) string {
+	return func(s string) string {
+		return PadWidthWith(s, c, n, opts)
+	}
+}
+
+// PadLeftWidth pads s on left side with c until it is n terminal columns wide.
+func PadLeftWidth(s, c string, n int) string {
+	return PadLeftWidthWith(s, c, n, WidthOptions{})
+}
+
+// PadLeftWidthF is the filter form of PadLeftWidth.
+func PadLeftWidthF(c string, n int) func(string) string {
+	return func(s string) string {
+		return PadLeftWidth(s, c, n)
+	}
+}
+
+// PadLeftWidthWith is like PadLeftWidth, measuring widths as WidthWith does
+// with opts.
+func PadLeftWidthWith(s, c string, n int, opts WidthOptions) string {
+	W := WidthWith(s, opts)
+	if W >= n {
+		return s
+	}
+	return repeatWidth(c, n-W, opts) + s
+}
+
+// PadLeftWidthWithF is the filter form of PadLeftWidthWith.
+func PadLeftWidthWithF(c string, n int, opts WidthOptions) func(string) string {
+	return func(s string) string {
+		return PadLeftWidthWith(s, c, n, opts)
+	}
+}
+
+// PadRightWidth pads s on right side with c until it is n terminal columns
+// wide.
+func PadRightWidth(s, c string, n int) string {
+	return PadRightWidthWith(s, c, n, WidthOptions{})
+}
+
+// PadRightWidthF is the filter form of PadRightWidth.
+func PadRightWidthF(c string, n int) func(string) string {
+	return func(s string) string {
+		return PadRightWidth(s, c, n)
+	}
+}
+
+// PadRightWidthWith is like PadRightWidth, measuring widths as WidthWith
+// does with opts.
+func PadRightWidthWith(s, c string, n int, opts WidthOptions) string {
+	W := WidthWith(s, opts)
+	if W >= n {
+		return s
+	}
+	return s + repeatWidth(c, n-W, opts)
+}
+
+// PadRightWidthWithF is the filter form of PadRightWidthWith.
+func PadRightWidthWithF(c string, n int, opts WidthOptions) func(string) string {
+	return func(s string) string {
+		return PadRightWidthWith(s, c, n, opts)
+	}
+}
+
 // Pipe pipes s through one or more string filters.
 func Pipe(s string, funcs ...func(string) string) string {
-	for _, fn := range funcs {
-		s = fn(s)
-	}
-	return s
+	return pipe(s, nil, funcs)
 }
 
 // QuoteItems quotes all items in array, mostly for debugging.
@@ -88,11 +174,23 @@ func ReplaceF(old, new string, n int) func(string) string {
 
 // ReplacePattern replaces string with regexp string.
 // ReplacePattern returns a copy of src, replacing matches of the Regexp with the replacement string repl. Inside repl, $ signs are interpreted as in Expand, so for instance $1 represents the text of the first submatch.
+// ReplacePattern panics if pattern is invalid; use ReplacePatternE for
+// patterns from untrusted input.
 func ReplacePattern(s, pattern, repl string) string {
-	r := regexp.MustCompile(pattern)
+	r := mustCompilePattern(pattern)
 	return r.ReplaceAllString(s, repl)
 }
 
+// ReplacePatternE is like ReplacePattern but returns an error if pattern is
+// invalid.
+func ReplacePatternE(s, pattern, repl string) (string, error) {
+	r, err := compilePattern(pattern)
+	if err != nil {
+		return "", err
+	}
+	return r.ReplaceAllString(s, repl), nil
+}
+
 // ReplacePatternF is the filter form of ReplaceRegexp.
 func ReplacePatternF(pattern, repl string) func(string) string {
 	return func(s string) string {
@@ -100,6 +198,29 @@ func ReplacePatternF(pattern, repl string) func(string) string {
 	}
 }
 
+// ReplacePatternFunc replaces each match of pattern in s with the result of
+// calling fn with its capture groups, keyed as by MatchGroups. It panics if
+// pattern is invalid.
+func ReplacePatternFunc(s, pattern string, fn func(groups map[string]string) string) string {
+	r := mustCompilePattern(pattern)
+	var b strings.Builder
+	last := 0
+	for _, loc := range r.FindAllStringSubmatchIndex(s, -1) {
+		b.WriteString(s[last:loc[0]])
+		b.WriteString(fn(patternGroups(r, s, loc)))
+		last = loc[1]
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// ReplacePatternFuncF is the filter form of ReplacePatternFunc.
+func ReplacePatternFuncF(pattern string, fn func(groups map[string]string) string) func(string) string {
+	return func(s string) string {
+		return ReplacePatternFunc(s, pattern, fn)
+	}
+}
+
 // Reverse a string
 func Reverse(s string) string {
 	cs := make([]rune, utf8.RuneCountInString(s))
@@ -111,6 +232,17 @@ func Reverse(s string) string {
 	return string(cs)
 }
 
+// ReverseGraphemes reverses s by grapheme cluster so combining marks and
+// emoji sequences stay attached to their base character.
+func ReverseGraphemes(s string) string {
+	bounds := graphemeBounds(s)
+	result := make([]byte, 0, len(s))
+	for i := len(bounds) - 1; i > 0; i-- {
+		result = append(result, s[bounds[i-1]:bounds[i]]...)
+	}
+	return string(result)
+}
+
 // Right returns the right substring of length n.
 func Right(s string, n int) string {
 	if n < 0 {
@@ -126,6 +258,37 @@ func RightF(n int) func(string) string {
 	}
 }
 
+// RightGraphemes returns the right substring of n grapheme clusters.
+func RightGraphemes(s string, n int) string {
+	if n < 0 {
+		return LeftGraphemes(s, -n)
+	}
+	bounds := graphemeBounds(s)
+	return substrBounds(s, bounds, len(bounds)-1-n, n)
+}
+
+// RightGraphemesF is the filter form of RightGraphemes.
+func RightGraphemesF(n int) func(string) string {
+	return func(s string) string {
+		return RightGraphemes(s, n)
+	}
+}
+
+// RightRunes returns the right substring of n runes.
+func RightRunes(s string, n int) string {
+	if n < 0 {
+		return LeftRunes(s, -n)
+	}
+	return SubstrRunes(s, utf8.RuneCountInString(s)-n, n)
+}
+
+// RightRunesF is the filter form of RightRunes.
+func RightRunesF(n int) func(string) string {
+	return func(s string) string {
+		return RightRunes(s, n)
+	}
+}
+
 // RightOf returns the substring to the right of prefix.
 func RightOf(s string, prefix string) string {
 	return Between(s, prefix, "")
@@ -133,8 +296,7 @@ func RightOf(s string, prefix string) string {
 
 // SetTemplateDelimiters sets the delimiters for Template function. Defaults to "{{" and "}}"
 func SetTemplateDelimiters(opening, closing string) {
-	templateOpen = opening
-	templateClose = closing
+	defaultTemplater.SetDelimiters(opening, closing)
 }
 
 // Slice slices a string. If end is negative then it is the from the end
@@ -157,6 +319,32 @@ func SliceF(start, end int) func(string) string {
 	}
 }
 
+// SliceGraphemes slices s by grapheme cluster. Negative start or end count
+// from the end of the string. Out of range indexes are clamped.
+func SliceGraphemes(s string, start, end int) string {
+	return sliceBounds(s, graphemeBounds(s), start, end)
+}
+
+// SliceGraphemesF is the filter form of SliceGraphemes.
+func SliceGraphemesF(start, end int) func(string) string {
+	return func(s string) string {
+		return SliceGraphemes(s, start, end)
+	}
+}
+
+// SliceRunes slices s by rune. Negative start or end count from the end of
+// the string. Out of range indexes are clamped.
+func SliceRunes(s string, start, end int) string {
+	return sliceBounds(s, runeBounds(s), start, end)
+}
+
+// SliceRunesF is the filter form of SliceRunes.
+func SliceRunesF(start, end int) func(string) string {
+	return func(s string) string {
+		return SliceRunes(s, start, end)
+	}
+}
+
 // SliceContains determines whether val is an element in slice.
 func SliceContains(slice []string, val string) bool {
 	if slice == nil {
@@ -185,12 +373,50 @@ func SliceIndexOf(slice []string, val string) int {
 	return -1
 }
 
-// Slugify converts s into a dasherized string suitable for URL segment.
+// Slugify joins the lower cased Words of s with dashes, dropping
+// punctuation and apostrophes, into a string suitable for a URL segment.
+// Letters of any script are kept, so Slugify("Crème Brûlée") is
+// "crème-brûlée".
 func Slugify(s string) string {
-	sl := slugifyRe.ReplaceAllString(s, "")
-	sl = strings.ToLower(sl)
-	sl = Dasherize(sl)
-	return sl
+	return joinWords(Words(s), "-", func(w string) string {
+		return apostrophes.Replace(lowerCase(w))
+	})
+}
+
+var apostrophes = strings.NewReplacer("'", "", "’", "")
+
+// SplitPattern splits s around the matches of pattern, as regexp.Split
+// does. If keep is true, each delimiter is kept as an element between the
+// parts it separates. SplitPattern panics if pattern is invalid.
+func SplitPattern(s, pattern string, keep bool) []string {
+	r := mustCompilePattern(pattern)
+	if s == "" {
+		return []string{""}
+	}
+	parts := []string{}
+	start, end := 0, 0
+	for _, loc := range r.FindAllStringIndex(s, -1) {
+		end = loc[0]
+		if loc[1] != 0 {
+			parts = append(parts, s[start:end])
+			if keep {
+				parts = append(parts, s[loc[0]:loc[1]])
+			}
+		}
+		start = loc[1]
+	}
+	if end != len(s) {
+		parts = append(parts, s[start:])
+	}
+	return parts
+}
+
+// SplitPatternF is the filter form of SplitPattern. It joins the parts
+// with sep, dropping the delimiters.
+func SplitPatternF(pattern, sep string) func(string) string {
+	return func(s string) string {
+		return strings.Join(SplitPattern(s, pattern, false), sep)
+	}
 }
 
 // StripPunctuation strips puncation from string.
@@ -206,7 +432,7 @@ func StripTags(s string, tags ...string) string {
 		tags = append(tags, "")
 	}
 	for _, tag := range tags {
-		stripTagsRe := regexp.MustCompile(`(?i)<\/?` + tag + `[^<>]*>`)
+		stripTagsRe := mustCompilePattern(`(?i)<\/?` + tag + `[^<>]*>`)
 		s = stripTagsRe.ReplaceAllString(s, "")
 	}
 	return s
@@ -235,148 +461,116 @@ func SubstrF(index, n int) func(string) string {
 	}
 }
 
-// Template is a string template which replaces template placeholders delimited
-// by "{{" and "}}" with values from map. The global delimiters may be set with
-// SetTemplateDelimiters.
-func Template(s string, values map[string]interface{}) string {
-	return TemplateWithDelimiters(s, values, templateOpen, templateClose)
+// SubstrGraphemes returns a substring of s starting at grapheme cluster index
+// of n grapheme clusters.
+func SubstrGraphemes(s string, index int, n int) string {
+	return substrBounds(s, graphemeBounds(s), index, n)
 }
 
-// TemplateDelimiters is the getter for the opening and closing delimiters for Template.
-func TemplateDelimiters() (opening string, closing string) {
-	return templateOpen, templateClose
+// SubstrGraphemesF is the filter form of SubstrGraphemes.
+func SubstrGraphemesF(index, n int) func(string) string {
+	return func(s string) string {
+		return SubstrGraphemes(s, index, n)
+	}
 }
 
-// TemplateWithDelimiters is string template with user-defineable opening and closing delimiters.
-func TemplateWithDelimiters(s string, values map[string]interface{}, opening, closing string) string {
-	escapeDelimiter := func(delim string) string {
-		result := templateRe.ReplaceAllString(delim, "\\$1")
-		return templateRe2.ReplaceAllString(result, "\\$")
-	}
-
-	openingDelim := escapeDelimiter(opening)
-	closingDelim := escapeDelimiter(closing)
-	r := regexp.MustCompile(openingDelim + `(.+?)` + closingDelim)
-	matches := r.FindAllStringSubmatch(s, -1)
-	for _, submatches := range matches {
-		match := submatches[0]
-		key := submatches[1]
-		//log.Printf("match %s key %s\n", match, key)
-		if values[key] != nil {
-			v := fmt.Sprintf("%v", values[key])
-			s = strings.Replace(s, match, v, -1)
-		}
-	}
+// SubstrRunes returns a substring of s starting at rune index of n runes.
+func SubstrRunes(s string, index int, n int) string {
+	return substrBounds(s, runeBounds(s), index, n)
+}
 
-	return s
+// SubstrRunesF is the filter form of SubstrRunes.
+func SubstrRunesF(index, n int) func(string) string {
+	return func(s string) string {
+		return SubstrRunes(s, index, n)
+	}
 }
 
-// ToArgv converts string s into an argv for exec.
-func ToArgv(s string) []string {
-	const (
-		InArg = iota
-		InArgQuote
-		OutOfArg
-	)
-	currentState := OutOfArg
-	currentQuoteChar := "\x00" // to distinguish between ' and " quotations
-	// this allows to use "foo'bar"
-	currentArg := ""
-	argv := []string{}
+// Template is a string template which replaces template placeholders delimited
+// by "{{" and "}}" with values from data. The global delimiters may be set with
+// SetTemplateDelimiters. Data is usually a map but may be any value accepted by
+// TemplateLookup, and placeholders may be paths like {{user.address.city}}.
+func Template(s string, data interface{}) string {
+	return defaultTemplater.Template(s, data)
+}
 
-	isQuote := func(c string) bool {
-		return c == `"` || c == `'`
-	}
+// TemplateDelimiters is the getter for the opening and closing delimiters for Template.
+func TemplateDelimiters() (opening string, closing string) {
+	return defaultTemplater.Delimiters()
+}
 
-	isEscape := func(c string) bool {
-		return c == `\`
-	}
+// TemplateWithDelimiters is string template with user-defineable opening and closing delimiters.
+// Placeholders without a value and malformed placeholders are left as is. Use
+// CompileTemplate to have them reported.
+func TemplateWithDelimiters(s string, data interface{}, opening, closing string) string {
+	opts := TemplateOptions{Opening: opening, Closing: closing}
+	t, _ := compileTemplate(s, opts, defaultFilters, true)
+	result, _ := t.Render(data)
+	return result
+}
 
-	isWhitespace := func(c string) bool {
-		return c == " " || c == "\t"
-	}
+// TitleLocale title cases each word of s, applying the casing rules of
+// locale: the first character of a word is title cased and the rest lower
+// cased. Text between words is left as is. See ToUpperLocale.
+func TitleLocale(s, locale string) string {
+	return casingFor(locale).title(s)
+}
 
-	L := len(s)
-	for i := 0; i < L; i++ {
-		c := s[i : i+1]
-
-		//fmt.Printf("c %s state %v arg %s argv %v i %d\n", c, currentState, currentArg, args, i)
-		if isQuote(c) {
-			switch currentState {
-			case OutOfArg:
-				currentArg = ""
-				fallthrough
-			case InArg:
-				currentState = InArgQuote
-				currentQuoteChar = c
-
-			case InArgQuote:
-				if c == currentQuoteChar {
-					currentState = InArg
-				} else {
-					currentArg += c
-				}
-			}
+// TitleLocaleF is the filter form of TitleLocale.
+func TitleLocaleF(locale string) func(string) string {
+	return func(s string) string {
+		return TitleLocale(s, locale)
+	}
+}
+
+// TitleStyle is the style guide Titleize follows.
+type TitleStyle int
+
+// Title styles.
+const (
+	// TitleSimple capitalizes every word.
+	TitleSimple TitleStyle = iota
+	// TitleAP follows the AP Stylebook: articles, and conjunctions and
+	// prepositions of three letters or fewer, are lower case unless they
+	// start or end the title.
+	TitleAP
+	// TitleChicago follows the Chicago Manual of Style: articles, all
+	// prepositions, "to", "as" and the conjunctions and, but, for, nor and or
+	// are lower case.
+	TitleChicago
+	// TitleAPA follows the APA style, which lower cases the same words as
+	// TitleAP but only capitalizes them at the start of a title or
+	// subtitle, so "What Are You Looking at".
+	TitleAPA
+)
 
-		} else if isWhitespace(c) {
-			switch currentState {
-			case InArg:
-				argv = append(argv, currentArg)
-				currentState = OutOfArg
-			case InArgQuote:
-				currentArg += c
-			case OutOfArg:
-				// nothing
-			}
+// Titleize converts s to title case following style. Minor words are lower
+// cased except as the first or, but for TitleAPA, last word of the title or
+// of a part of it, such as a subtitle after a colon; the other words are
+// capitalized. Words
+// with upper case letters after the first, such as "NASA" or "iPhone", are
+// kept as is unless all of s is upper case. Each part of a hyphenated
+// compound is cased as a word, so "state-of-the-art" becomes
+// "State-of-the-Art".
+func Titleize(s string, style TitleStyle) string {
+	return titleize(s, style)
+}
 
-		} else if isEscape(c) {
-			switch currentState {
-			case OutOfArg:
-				currentArg = ""
-				currentState = InArg
-				fallthrough
-			case InArg:
-				fallthrough
-			case InArgQuote:
-				if i == L-1 {
-					if runtime.GOOS == "windows" {
-						// just add \ to end for windows
-						currentArg += c
-					} else {
-						panic("Escape character at end string")
-					}
-				} else {
-					if runtime.GOOS == "windows" {
-						peek := s[i+1 : i+2]
-						if peek != `"` {
-							currentArg += c
-						}
-					} else {
-						i++
-						c = s[i : i+1]
-						currentArg += c
-					}
-				}
-			}
-		} else {
-			switch currentState {
-			case InArg, InArgQuote:
-				currentArg += c
-
-			case OutOfArg:
-				currentArg = ""
-				currentArg += c
-				currentState = InArg
-			}
-		}
+// TitleizeF is the filter form of Titleize.
+func TitleizeF(style TitleStyle) func(string) string {
+	return func(s string) string {
+		return Titleize(s, style)
 	}
+}
 
-	if currentState == InArg {
-		argv = append(argv, currentArg)
-	} else if currentState == InArgQuote {
-		panic("Starting quote has no ending quote.")
+// ToArgv converts string s into an argv for exec. ToArgv panics on an
+// unterminated quote or a trailing escape; use ParseArgv to get an error
+// instead.
+func ToArgv(s string) []string {
+	argv, err := ParseArgv(s)
+	if err != nil {
+		panic(err)
 	}
-
 	return argv
 }
 
@@ -425,87 +619,275 @@ func ToFloat64Or(s string, defaultValue float64) float64 {
 // ToFloatOr parses as a float64 or returns defaultValue.
 var ToFloatOr = ToFloat64Or
 
-// TODO This is not working yet. Go's regexp package does not have some
-// of the niceities in JavaScript
-//
-// Truncate truncates the string, accounting for word placement and chars count
-// adding a morestr (defaults to ellipsis)
-// func Truncate(s, morestr string, n int) string {
-// 	L := len(s)
-// 	if L <= n {
-// 		return s
-// 	}
-//
-// 	if morestr == "" {
-// 		morestr = "..."
-// 	}
-//
-// 	tmpl := func(c string) string {
-// 		if strings.ToUpper(c) != strings.ToLower(c) {
-// 			return "A"
-// 		}
-// 		return " "
-// 	}
-// 	template := s[0 : n+1]
-// 	var truncateRe = regexp.MustCompile(`.(?=\W*\w*$)`)
-// 	truncateRe.ReplaceAllStringFunc(template, tmpl) // 'Hello, world' -> 'HellAA AAAAA'
-// 	var wwRe = regexp.MustCompile(`\w\w`)
-// 	var whitespaceRe2 = regexp.MustCompile(`\s*\S+$`)
-// 	if wwRe.MatchString(template[len(template)-2:]) {
-// 		template = whitespaceRe2.ReplaceAllString(template, "")
-// 	} else {
-// 		template = strings.TrimRight(template, " \t\n")
-// 	}
-//
-// 	if len(template+morestr) > L {
-// 		return s
-// 	}
-// 	return s[0:len(template)] + morestr
-// }
-//
-//     truncate: function(length, pruneStr) { //from underscore.string, author: github.com/rwz
-//       var str = this.s;
-//
-//       length = ~~length;
-//       pruneStr = pruneStr || '...';
-//
-//       if (str.length <= length) return new this.constructor(str);
-//
-//       var tmpl = function(c){ return c.toUpperCase() !== c.toLowerCase() ? 'A' : ' '; },
-//         template = str.slice(0, length+1).replace(/.(?=\W*\w*$)/g, tmpl); // 'Hello, world' -> 'HellAA AAAAA'
-//
-//       if (template.slice(template.length-2).match(/\w\w/))
-//         template = template.replace(/\s*\S+$/, '');
-//       else
-//         template = new S(template.slice(0, template.length-1)).trimRight().s;
-//
-//       return (template+pruneStr).length > str.length ? new S(str) : new S(str.slice(0, template.length)+pruneStr);
-//     },
-
-// Underscore returns converted camel cased string into a string delimited by underscores.
-func Underscore(s string) string {
-	if s == "" {
-		return ""
+// ToLowerLocale lower cases s with the casing rules of locale. See
+// ToUpperLocale.
+func ToLowerLocale(s, locale string) string {
+	runes := []rune(s)
+	return casingFor(locale).toLower(runes, 0, len(runes))
+}
+
+// ToLowerLocaleF is the filter form of ToLowerLocale.
+func ToLowerLocaleF(locale string) func(string) string {
+	return func(s string) string {
+		return ToLowerLocale(s, locale)
+	}
+}
+
+// ToUpperLocale upper cases s with the casing rules of locale, a language
+// tag such as "tr" or "tr-TR". Turkish and Azerbaijani ("tr", "az") map i to
+// İ and ı to I, and Lithuanian ("lt") keeps or drops the dot of i and j
+// under accents. Other locales use Unicode's default mappings, including
+// those that expand such as "ß" to "SS", and the final form of Greek sigma
+// when lower casing.
+func ToUpperLocale(s, locale string) string {
+	runes := []rune(s)
+	return casingFor(locale).toUpper(runes, 0, len(runes))
+}
+
+// ToUpperLocaleF is the filter form of ToUpperLocale.
+func ToUpperLocaleF(locale string) func(string) string {
+	return func(s string) string {
+		return ToUpperLocale(s, locale)
+	}
+}
+
+// TruncatePosition is where Truncate removes text from.
+type TruncatePosition int
+
+// Truncate positions.
+const (
+	TruncateAtEnd TruncatePosition = iota
+	TruncateAtStart
+	TruncateAtMiddle
+)
+
+// TruncateOptions configures TruncateWith.
+type TruncateOptions struct {
+	// Omission replaces the removed text. Defaults to "...".
+	Omission string
+	// Position is where text is removed from.
+	Position TruncatePosition
+	// Width measures n in terminal columns, as by Width, instead of runes.
+	Width bool
+	// AmbiguousIsWide counts East Asian ambiguous characters as two
+	// columns when Width is set. See WidthOptions.
+	AmbiguousIsWide bool
+}
+
+// Truncate shortens s to at most n runes including the omission marker more
+// (defaults to "..."). Truncate never cuts inside a word when an earlier word
+// boundary exists and never splits a grapheme cluster.
+func Truncate(s string, n int, more string) string {
+	return TruncateWith(s, n, TruncateOptions{Omission: more})
+}
+
+// TruncateF is the filter form of Truncate.
+func TruncateF(n int, more string) func(string) string {
+	return func(s string) string {
+		return Truncate(s, n, more)
 	}
-	u := strings.TrimSpace(s)
+}
 
-	u = underscoreRe.ReplaceAllString(u, "${1}_$2")
-	u = dashSpaceRe.ReplaceAllString(u, "_")
-	u = strings.ToLower(u)
-	if IsUpper(s[0:1]) {
-		return "_" + u
+// TruncateMiddle is like Truncate but removes text from the middle of s,
+// which keeps both ends of long paths readable.
+func TruncateMiddle(s string, n int, more string) string {
+	return TruncateWith(s, n, TruncateOptions{Omission: more, Position: TruncateAtMiddle})
+}
+
+// TruncateMiddleF is the filter form of TruncateMiddle.
+func TruncateMiddleF(n int, more string) func(string) string {
+	return func(s string) string {
+		return TruncateMiddle(s, n, more)
 	}
-	return u
+}
+
+// TruncateStart is like Truncate but removes text from the start of s.
+func TruncateStart(s string, n int, more string) string {
+	return TruncateWith(s, n, TruncateOptions{Omission: more, Position: TruncateAtStart})
+}
+
+// TruncateStartF is the filter form of TruncateStart.
+func TruncateStartF(n int, more string) func(string) string {
+	return func(s string) string {
+		return TruncateStart(s, n, more)
+	}
+}
+
+// TruncateWith shortens s to at most n runes, or n columns if opts.Width is
+// set, including the omission marker.
+func TruncateWith(s string, n int, opts TruncateOptions) string {
+	more := opts.Omission
+	if more == "" {
+		more = "..."
+	}
+
+	t := newTruncater(s, opts)
+	if t.weight(s) <= n {
+		return s
+	}
+	m := newTruncater(more, opts)
+	budget := n - m.weight(more)
+	if budget <= 0 {
+		return more[:m.bounds[m.prefix(n)]]
+	}
+
+	switch opts.Position {
+	case TruncateAtStart:
+		return more + t.tail(budget)
+	case TruncateAtMiddle:
+		head := t.head(budget / 2)
+		return head + more + t.tail(budget-t.weight(head))
+	}
+	return t.head(budget) + more
+}
+
+// TruncateWithF is the filter form of TruncateWith.
+func TruncateWithF(n int, opts TruncateOptions) func(string) string {
+	return func(s string) string {
+		return TruncateWith(s, n, opts)
+	}
+}
+
+// truncater cuts a string made of weighted units at word boundaries.
+type truncater struct {
+	s       string
+	bounds  []int
+	weights []int
+}
+
+// newTruncater splits s into grapheme clusters weighted by their rune count,
+// or by their terminal width if opts.Width is set.
+func newTruncater(s string, opts TruncateOptions) *truncater {
+	t := &truncater{s: s}
+	if opts.Width {
+		t.bounds, t.weights = widthBounds(s, WidthOptions{AmbiguousIsWide: opts.AmbiguousIsWide})
+		return t
+	}
+	t.bounds = graphemeBounds(s)
+	for i := 1; i < len(t.bounds); i++ {
+		t.weights = append(t.weights, utf8.RuneCountInString(s[t.bounds[i-1]:t.bounds[i]]))
+	}
+	return t
+}
+
+// isWord reports whether unit i is part of a word.
+func (t *truncater) isWord(i int) bool {
+	r, _ := utf8.DecodeRuneInString(t.s[t.bounds[i]:])
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// isBoundary reports whether cutting before unit i does not split a word.
+func (t *truncater) isBoundary(i int) bool {
+	return i == 0 || i == len(t.weights) || !t.isWord(i-1) || !t.isWord(i)
+}
+
+// weight sums the weights of the units making up prefix or suffix sub.
+func (t *truncater) weight(sub string) int {
+	w := 0
+	for i := 0; i < len(t.weights) && t.bounds[i+1] <= len(sub); i++ {
+		w += t.weights[i]
+	}
+	return w
+}
+
+// prefix returns the number of leading units weighing at most budget.
+func (t *truncater) prefix(budget int) int {
+	k := 0
+	for w := 0; k < len(t.weights) && w+t.weights[k] <= budget; k++ {
+		w += t.weights[k]
+	}
+	return k
+}
+
+// head returns the longest prefix weighing at most budget that ends on a
+// word boundary, or a hard cut if the first word alone exceeds budget.
+func (t *truncater) head(budget int) string {
+	k := t.prefix(budget)
+	j := k
+	for j > 0 && !t.isBoundary(j) {
+		j--
+	}
+	if head := strings.TrimRightFunc(t.s[:t.bounds[j]], unicode.IsSpace); head != "" {
+		return head
+	}
+	return t.s[:t.bounds[k]]
+}
+
+// tail returns the longest suffix weighing at most budget that starts on a
+// word boundary, or a hard cut if the last word alone exceeds budget.
+func (t *truncater) tail(budget int) string {
+	L := len(t.weights)
+	m := L
+	for w := 0; m > 0 && w+t.weights[m-1] <= budget; m-- {
+		w += t.weights[m-1]
+	}
+	j := m
+	for j < L && !t.isBoundary(j) {
+		j++
+	}
+	if tail := strings.TrimLeftFunc(t.s[t.bounds[j]:], unicode.IsSpace); tail != "" {
+		return tail
+	}
+	return t.s[t.bounds[m]:]
+}
+
+// Underscore joins the lower cased Words of s with underscores, as in
+// "data_rate". A leading "-", "_" or upper case letter in s becomes a
+// leading underscore, so Underscore("FooBar") is "_foo_bar".
+func Underscore(s string) string {
+	return defaultCaseConverter.Underscore(s)
 }
 
 // UnescapeHTML is an alias for html.UnescapeString.
 func UnescapeHTML(s string) string {
-	if Verbose {
-		fmt.Println("Use html.UnescapeString instead of UnescapeHTML")
-	}
+	deprecated("UnescapeHTML", "html.UnescapeString")
 	return html.UnescapeString(s)
 }
 
+// Width returns the number of columns s occupies in a terminal. East Asian
+// wide characters and emoji count as two columns, combining marks and other
+// zero-width characters count as none, and ANSI escape sequences are
+// ignored. East Asian ambiguous characters count as one column; see
+// WidthWith.
+func Width(s string) int {
+	return WidthWith(s, WidthOptions{})
+}
+
+// WidthOptions configures WidthWith.
+type WidthOptions struct {
+	// AmbiguousIsWide counts East Asian ambiguous characters (Greek,
+	// Cyrillic, box drawing, ...) as two columns, which is how terminals
+	// configured for CJK locales render them.
+	AmbiguousIsWide bool
+}
+
+// WidthWith is like Width, measuring with opts.
+func WidthWith(s string, opts WidthOptions) int {
+	_, widths := widthBounds(s, opts)
+	W := 0
+	for _, w := range widths {
+		W += w
+	}
+	return W
+}
+
+// Words splits s into words, as used by the case converters. Words are
+// separated by whitespace, punctuation and symbols such as "_" and "-", and
+// a new word starts at an upper case letter following a lower case letter
+// or digit ("fooBar", "base64Encode") and at the last letter of an upper
+// case run followed by a lower case letter ("XMLHttp"). Digits otherwise
+// belong to the word they follow or begin, and apostrophes within a word are
+// kept.
+func Words(s string) []string {
+	return (*casing)(nil).words(s)
+}
+
+// WordsLocale is like Words but keeps letters that locale spells with two
+// runes together, such as the Dutch ("nl") "IJ" in "IJsselMeer".
+func WordsLocale(s, locale string) []string {
+	return casingFor(locale).words(s)
+}
+
 // WrapHTML wraps s within HTML tag having attributes attrs. Note,
 // WrapHTML does not escape s value.
 func WrapHTML(s string, tag string, attrs map[string]string) string {
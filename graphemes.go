@@ -0,0 +1,220 @@
+package str
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Grapheme_Cluster_Break property values from UAX #29. Only the values
+// needed by the extended grapheme cluster rules are distinguished.
+const (
+	gbOther = iota
+	gbCR
+	gbLF
+	gbControl
+	gbExtend
+	gbZWJ
+	gbRegionalIndicator
+	gbPrepend
+	gbSpacingMark
+	gbL
+	gbV
+	gbT
+	gbLV
+	gbLVT
+)
+
+// graphemeProperty approximates the Grapheme_Cluster_Break property of r
+// using the general categories available in package unicode.
+func graphemeProperty(r rune) int {
+	switch {
+	case r == '\r':
+		return gbCR
+	case r == '\n':
+		return gbLF
+	case r == 0x200D:
+		return gbZWJ
+	case r == 0x200C, r == 0xFF9E, r == 0xFF9F:
+		return gbExtend
+	case r >= 0x1F3FB && r <= 0x1F3FF:
+		// emoji skin tone modifiers
+		return gbExtend
+	case r >= 0xE0020 && r <= 0xE007F:
+		// emoji tag sequences
+		return gbExtend
+	case r >= 0x1F1E6 && r <= 0x1F1FF:
+		return gbRegionalIndicator
+	case r >= 0x0600 && r <= 0x0605, r == 0x06DD, r == 0x070F,
+		r == 0x0890, r == 0x0891, r == 0x08E2, r == 0x110BD, r == 0x110CD:
+		return gbPrepend
+	case r >= 0x1100 && r <= 0x115F, r >= 0xA960 && r <= 0xA97C:
+		return gbL
+	case r >= 0x1160 && r <= 0x11A7, r >= 0xD7B0 && r <= 0xD7C6:
+		return gbV
+	case r >= 0x11A8 && r <= 0x11FF, r >= 0xD7CB && r <= 0xD7FB:
+		return gbT
+	case r >= 0xAC00 && r <= 0xD7A3:
+		if (r-0xAC00)%28 == 0 {
+			return gbLV
+		}
+		return gbLVT
+	case unicode.In(r, unicode.Mn, unicode.Me):
+		return gbExtend
+	case unicode.Is(unicode.Mc, r):
+		return gbSpacingMark
+	case unicode.In(r, unicode.Cc, unicode.Cf, unicode.Zl, unicode.Zp):
+		return gbControl
+	}
+	return gbOther
+}
+
+// isPictographic approximates the Extended_Pictographic property.
+func isPictographic(r rune) bool {
+	switch {
+	case r == 0x00A9, r == 0x00AE, r == 0x203C, r == 0x2049, r == 0x2122,
+		r == 0x2139, r == 0x3030, r == 0x303D, r == 0x3297, r == 0x3299:
+		return true
+	case r >= 0x2194 && r <= 0x21AA:
+		return true
+	case r >= 0x2300 && r <= 0x23FF:
+		return true
+	case r >= 0x25AA && r <= 0x27BF:
+		return true
+	case r >= 0x2934 && r <= 0x2935, r >= 0x2B05 && r <= 0x2B55:
+		return true
+	case r >= 0x1F000 && r <= 0x1F0FF:
+		return true
+	case r >= 0x1F10D && r <= 0x1F1AD:
+		return true
+	case r >= 0x1F200 && r <= 0x1F3FA:
+		return true
+	case r >= 0x1F400 && r <= 0x1FAFF:
+		return true
+	case r >= 0x1FC00 && r <= 0x1FFFD:
+		return true
+	}
+	return false
+}
+
+// graphemeLen returns the length in bytes of the first extended grapheme
+// cluster in s.
+func graphemeLen(s string) int {
+	r, i := utf8.DecodeRuneInString(s)
+	if i == 0 {
+		return 0
+	}
+	prev := graphemeProperty(r)
+
+	// emoji tracks GB11: 1 after Extended_Pictographic Extend*, 2 once a
+	// ZWJ follows that sequence.
+	emoji := 0
+	if isPictographic(r) {
+		emoji = 1
+	}
+	regional := 0
+	if prev == gbRegionalIndicator {
+		regional = 1
+	}
+
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		next := graphemeProperty(r)
+		if graphemeBreak(prev, next, emoji == 2 && isPictographic(r), regional) {
+			break
+		}
+
+		switch {
+		case isPictographic(r):
+			emoji = 1
+		case next == gbExtend && emoji == 1:
+		case next == gbZWJ && emoji == 1:
+			emoji = 2
+		default:
+			emoji = 0
+		}
+		if next == gbRegionalIndicator {
+			regional++
+		}
+		prev = next
+		i += size
+	}
+	return i
+}
+
+// graphemeBreak reports whether there is a cluster boundary between two
+// adjacent runes with properties prev and next.
+func graphemeBreak(prev, next int, emojiJoin bool, regional int) bool {
+	switch {
+	case prev == gbCR && next == gbLF:
+		return false
+	case prev == gbCR || prev == gbLF || prev == gbControl:
+		return true
+	case next == gbCR || next == gbLF || next == gbControl:
+		return true
+	case prev == gbL && (next == gbL || next == gbV || next == gbLV || next == gbLVT):
+		return false
+	case (prev == gbLV || prev == gbV) && (next == gbV || next == gbT):
+		return false
+	case (prev == gbLVT || prev == gbT) && next == gbT:
+		return false
+	case next == gbExtend || next == gbZWJ || next == gbSpacingMark:
+		return false
+	case prev == gbPrepend:
+		return false
+	case prev == gbZWJ && emojiJoin:
+		return false
+	case prev == gbRegionalIndicator && next == gbRegionalIndicator:
+		return regional%2 == 0
+	}
+	return true
+}
+
+// graphemeBounds returns the byte offset of each grapheme cluster in s
+// followed by len(s).
+func graphemeBounds(s string) []int {
+	bounds := []int{}
+	for i := 0; i < len(s); i += graphemeLen(s[i:]) {
+		bounds = append(bounds, i)
+	}
+	return append(bounds, len(s))
+}
+
+// runeBounds returns the byte offset of each rune in s followed by len(s).
+func runeBounds(s string) []int {
+	bounds := []int{}
+	for i := range s {
+		bounds = append(bounds, i)
+	}
+	return append(bounds, len(s))
+}
+
+// substrBounds is Substr counting in the units delimited by bounds.
+func substrBounds(s string, bounds []int, index, n int) string {
+	L := len(bounds) - 1
+	if index < 0 || index >= L || s == "" {
+		return ""
+	}
+	end := index + n
+	if end >= L {
+		end = L
+	}
+	if end <= index {
+		return ""
+	}
+	return s[bounds[index]:bounds[end]]
+}
+
+// sliceBounds is Slice counting in the units delimited by bounds. Negative
+// indexes count from the end and out of range indexes are clamped.
+func sliceBounds(s string, bounds []int, start, end int) string {
+	L := len(bounds) - 1
+	if start < 0 {
+		start += L
+	}
+	if end < 0 {
+		end += L
+	}
+	start = max(0, min(start, L))
+	end = max(start, min(end, L))
+	return s[bounds[start]:bounds[end]]
+}
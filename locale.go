@@ -0,0 +1,268 @@
+package str
+
+import (
+	"strings"
+	"unicode"
+)
+
+// casing holds the rules of a locale that differ from Unicode's default
+// case mappings. The upper and lower rules map runes[i] in context and
+// report whether they apply. A nil rule or *casing applies the default
+// mappings.
+type casing struct {
+	upper func(runes []rune, i int) (string, bool)
+	lower func(runes []rune, i int) (string, bool)
+	// titleFirst title cases the start of the word runes, returning the
+	// result and the number of runes it replaces, or 0 if it does not apply.
+	titleFirst func(runes []rune) (string, int)
+	// digraph reports whether runes[i-1] and runes[i] are one letter that
+	// Words must not split.
+	digraph func(runes []rune, i int) bool
+}
+
+// localeCasings maps language codes to their casing rules.
+var localeCasings = map[string]*casing{
+	"az": turkicCasing,
+	"lt": lithuanianCasing,
+	"nl": dutchCasing,
+	"tr": turkicCasing,
+}
+
+// casingFor returns the casing rules for the language of locale, such as
+// "tr" for "tr-TR", or nil if it uses the default mappings.
+func casingFor(locale string) *casing {
+	lang := strings.ToLower(locale)
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	return localeCasings[lang]
+}
+
+// Turkish and Azerbaijani distinguish dotted i/İ from dotless ı/I.
+var turkicCasing = &casing{
+	upper: func(runes []rune, i int) (string, bool) {
+		if runes[i] == 'i' {
+			return "İ", true
+		}
+		return "", false
+	},
+	lower: func(runes []rune, i int) (string, bool) {
+		switch {
+		case runes[i] == 'İ':
+			return "i", true
+		case runes[i] == 'I' && i+1 < len(runes) && runes[i+1] == '̇':
+			return "i", true
+		case runes[i] == 'I':
+			return "ı", true
+		case runes[i] == '̇' && i > 0 && runes[i-1] == 'I':
+			return "", true
+		}
+		return "", false
+	},
+}
+
+// Lithuanian keeps the dot of i and j under an accent when lower casing and
+// drops it when upper casing.
+var lithuanianCasing = &casing{
+	upper: func(runes []rune, i int) (string, bool) {
+		if runes[i] == '̇' && i > 0 && strings.ContainsRune("ijįɨʝ", runes[i-1]) {
+			return "", true
+		}
+		return "", false
+	},
+	lower: func(runes []rune, i int) (string, bool) {
+		moreAbove := i+1 < len(runes) && unicode.Is(unicode.Mn, runes[i+1])
+		switch runes[i] {
+		case 'I':
+			return "i̇", moreAbove
+		case 'J':
+			return "j̇", moreAbove
+		case 'Į':
+			return "į̇", moreAbove
+		case 'Ì':
+			return "i̇̀", true
+		case 'Í':
+			return "i̇́", true
+		case 'Ĩ':
+			return "i̇̃", true
+		}
+		return "", false
+	},
+}
+
+// Dutch title cases the digraph "ij" as "IJ", as in "IJsselmeer".
+var dutchCasing = &casing{
+	titleFirst: func(runes []rune) (string, int) {
+		if len(runes) > 2 && unicode.ToLower(runes[0]) == 'i' && unicode.ToLower(runes[1]) == 'j' && unicode.IsLetter(runes[2]) {
+			return "IJ", 2
+		}
+		return "", 0
+	},
+	digraph: func(runes []rune, i int) bool {
+		return runes[i-1] == 'I' && runes[i] == 'J'
+	},
+}
+
+// specialUpper and specialTitle hold the unconditional mappings of
+// Unicode's SpecialCasing.txt that expand to more than one rune and so are
+// missing from unicode.ToUpper and unicode.ToTitle.
+var specialUpper = map[rune]string{
+	'ß': "SS", 'ŉ': "ʼN", 'ǰ': "J̌", 'ΐ': "Ϊ́", 'ΰ': "Ϋ́", 'և': "ԵՒ",
+	'ẖ': "H̱", 'ẗ': "T̈", 'ẘ': "W̊", 'ẙ': "Y̊", 'ẚ': "Aʾ",
+	'ﬀ': "FF", 'ﬁ': "FI", 'ﬂ': "FL", 'ﬃ': "FFI", 'ﬄ': "FFL", 'ﬅ': "ST", 'ﬆ': "ST",
+	'ﬓ': "ՄՆ", 'ﬔ': "ՄԵ", 'ﬕ': "ՄԻ", 'ﬖ': "ՎՆ", 'ﬗ': "ՄԽ",
+}
+
+var specialTitle = map[rune]string{
+	'ß': "Ss", 'ŉ': "ʼN", 'ǰ': "J̌", 'ΐ': "Ϊ́", 'ΰ': "Ϋ́", 'և': "Եւ",
+	'ẖ': "H̱", 'ẗ': "T̈", 'ẘ': "W̊", 'ẙ': "Y̊", 'ẚ': "Aʾ",
+	'ﬀ': "Ff", 'ﬁ': "Fi", 'ﬂ': "Fl", 'ﬃ': "Ffi", 'ﬄ': "Ffl", 'ﬅ': "St", 'ﬆ': "St",
+	'ﬓ': "Մն", 'ﬔ': "Մե", 'ﬕ': "Մի", 'ﬖ': "Վն", 'ﬗ': "Մխ",
+}
+
+// toUpper upper cases runes[start:end], expanding runes such as "ß" to
+// "SS".
+func (c *casing) toUpper(runes []rune, start, end int) string {
+	var b strings.Builder
+	for i := start; i < end; i++ {
+		if m, ok := c.upperRule(runes, i); ok {
+			b.WriteString(m)
+			continue
+		}
+		if m, ok := specialUpper[runes[i]]; ok {
+			b.WriteString(m)
+		} else {
+			b.WriteRune(unicode.ToUpper(runes[i]))
+		}
+	}
+	return b.String()
+}
+
+// toLower lower cases runes[start:end]. A capital sigma ending a word
+// lower cases to the final form "ς" rather than "σ".
+func (c *casing) toLower(runes []rune, start, end int) string {
+	var b strings.Builder
+	for i := start; i < end; i++ {
+		if c != nil && c.lower != nil {
+			if m, ok := c.lower(runes, i); ok {
+				b.WriteString(m)
+				continue
+			}
+		}
+		if runes[i] == 'Σ' && isFinalSigma(runes, i) {
+			b.WriteRune('ς')
+		} else {
+			b.WriteRune(unicode.ToLower(runes[i]))
+		}
+	}
+	return b.String()
+}
+
+// titleWord title cases the first character of w and lower cases the rest.
+func (c *casing) titleWord(w string) string {
+	runes := []rune(w)
+	if len(runes) == 0 {
+		return ""
+	}
+	if c != nil && c.titleFirst != nil {
+		if first, n := c.titleFirst(runes); n > 0 {
+			return first + c.toLower(runes, n, len(runes))
+		}
+	}
+	// The first character takes its combining marks with it.
+	n := 1
+	for n < len(runes) && unicode.Is(unicode.Mn, runes[n]) {
+		n++
+	}
+	first, ok := c.upperRule(runes, 0)
+	switch {
+	case ok:
+	case specialTitle[runes[0]] != "":
+		first = specialTitle[runes[0]]
+	default:
+		first = string(unicode.ToTitle(runes[0]))
+	}
+	return first + c.toUpper(runes, 1, n) + c.toLower(runes, n, len(runes))
+}
+
+// upperRule applies the locale's upper case rule to runes[i], if any.
+// Locale rules upper case a rune as they title case it.
+func (c *casing) upperRule(runes []rune, i int) (string, bool) {
+	if c == nil || c.upper == nil {
+		return "", false
+	}
+	return c.upper(runes, i)
+}
+
+// title title cases each word of s, leaving the text between words intact.
+func (c *casing) title(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i := 0; i < len(runes); {
+		j := i
+		for j < len(runes) && isWordRune(runes, j) {
+			j++
+		}
+		if j > i {
+			b.WriteString(c.titleWord(string(runes[i:j])))
+			i = j
+			continue
+		}
+		b.WriteRune(runes[i])
+		i++
+	}
+	return b.String()
+}
+
+// lowerCase and upperCase lower and upper case all of s.
+func (c *casing) lowerCase(s string) string {
+	runes := []rune(s)
+	return c.toLower(runes, 0, len(runes))
+}
+
+func (c *casing) upperCase(s string) string {
+	runes := []rune(s)
+	return c.toUpper(runes, 0, len(runes))
+}
+
+// lowerCase, upperCase and titleWord apply the default mappings.
+func lowerCase(s string) string {
+	return (*casing)(nil).lowerCase(s)
+}
+
+func upperCase(s string) string {
+	return (*casing)(nil).upperCase(s)
+}
+
+func titleWord(w string) string {
+	return (*casing)(nil).titleWord(w)
+}
+
+// isFinalSigma implements the Final_Sigma condition of SpecialCasing.txt:
+// runes[i] follows a cased letter and is not followed by one, ignoring
+// marks and other case-ignorable runes in between.
+func isFinalSigma(runes []rune, i int) bool {
+	before, after := false, false
+	for j := i - 1; j >= 0; j-- {
+		if !isCaseIgnorable(runes[j]) {
+			before = isCased(runes[j])
+			break
+		}
+	}
+	for j := i + 1; j < len(runes); j++ {
+		if !isCaseIgnorable(runes[j]) {
+			after = isCased(runes[j])
+			break
+		}
+	}
+	return before && !after
+}
+
+func isCased(r rune) bool {
+	return unicode.IsUpper(r) || unicode.IsLower(r) || unicode.IsTitle(r)
+}
+
+func isCaseIgnorable(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf, unicode.Lm, unicode.Sk) ||
+		r == '\'' || r == '’' || r == '.' || r == ':' || r == '·'
+}
@@ -0,0 +1,309 @@
+package str
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Filter is a string filter. Every F function returns a value assignable
+// to Filter, so filters can be combined, stored and passed around instead of
+// nesting Pipe calls.
+type Filter func(string) string
+
+// Compose returns a Filter that pipes its input through filters in order.
+func Compose(filters ...func(string) string) Filter {
+	return func(s string) string {
+		return Pipe(s, filters...)
+	}
+}
+
+// Then returns a Filter that applies f and then next.
+func (f Filter) Then(next func(string) string) Filter {
+	return Compose(f, next)
+}
+
+// When returns a Filter that applies f only to strings satisfying pred and
+// passes other strings through unchanged.
+func When(pred func(string) bool, f func(string) string) Filter {
+	return func(s string) string {
+		if pred(s) {
+			return f(s)
+		}
+		return s
+	}
+}
+
+// Unless returns a Filter that applies f only to strings not satisfying
+// pred.
+func Unless(pred func(string) bool, f func(string) string) Filter {
+	return func(s string) string {
+		if pred(s) {
+			return s
+		}
+		return f(s)
+	}
+}
+
+// OnLines returns a Filter that applies f to each line of its input as split
+// by Lines. Lines are joined with "\n", so Windows newlines are normalized.
+func OnLines(f func(string) string) Filter {
+	return func(s string) string {
+		return strings.Join(Map(Lines(s), f), "\n")
+	}
+}
+
+// OnWords returns a Filter that applies f to each run of non-whitespace in
+// its input, leaving the whitespace between words intact.
+func OnWords(f func(string) string) Filter {
+	return func(s string) string {
+		var b strings.Builder
+		for s != "" {
+			i := strings.IndexFunc(s, unicode.IsSpace)
+			if i < 0 {
+				i = len(s)
+			}
+			if i > 0 {
+				b.WriteString(f(s[:i]))
+			}
+			s = s[i:]
+			i = strings.IndexFunc(s, func(r rune) bool {
+				return !unicode.IsSpace(r)
+			})
+			if i < 0 {
+				i = len(s)
+			}
+			b.WriteString(s[:i])
+			s = s[i:]
+		}
+		return b.String()
+	}
+}
+
+// OnMatches returns a Filter that replaces each match of regexp pattern with
+// the result of applying f to it. Like ReplacePatternF, OnMatches panics if
+// pattern is invalid.
+func OnMatches(pattern string, f func(string) string) Filter {
+	r := mustCompilePattern(pattern)
+	return func(s string) string {
+		return r.ReplaceAllStringFunc(s, f)
+	}
+}
+
+// Tee returns a Filter that passes its input to fn, for logging or
+// collecting intermediate values, and returns the input unchanged.
+func Tee(fn func(string)) Filter {
+	return func(s string) string {
+		fn(s)
+		return s
+	}
+}
+
+// PipeError reports the stage of a pipeline that failed.
+type PipeError struct {
+	// Index is the position of the failing stage, starting at 0.
+	Index int
+	// Name identifies the stage. It is the name given to Named, or else
+	// derived from the function, such as "ReplacePatternF".
+	Name string
+	// Err is the error returned by the stage.
+	Err error
+}
+
+func (e *PipeError) Error() string {
+	return fmt.Sprintf("pipe stage %d (%s): %v", e.Index, e.Name, e.Err)
+}
+
+// Unwrap returns the error returned by the stage.
+func (e *PipeError) Unwrap() error {
+	return e.Err
+}
+
+// PipeE pipes s through one or more string filters that can fail. PipeE
+// stops at the first failing stage and returns a *PipeError wrapping its
+// error. Use Lift to include filters such as BetweenF.
+func PipeE(s string, funcs ...func(string) (string, error)) (string, error) {
+	stages := make([]Stage, len(funcs))
+	for i, fn := range funcs {
+		stages[i].Fn = fn
+	}
+	return PipeStages(s, stages...)
+}
+
+// Stage is a string filter that can fail, named for PipeStages.
+type Stage struct {
+	// Name identifies the stage in a *PipeError and a TraceEvent. If empty,
+	// the stage is named as by PipeE.
+	Name string
+	Fn   func(string) (string, error)
+}
+
+// PipeStages is like PipeE but names each stage, so a Tracer sees the name
+// of stages that succeed as well as of those that fail.
+func PipeStages(s string, stages ...Stage) (string, error) {
+	t := currentTracer()
+	for i, st := range stages {
+		start := time.Now()
+		result, err := st.Fn(s)
+		if t != nil {
+			name, serr := st.result(err)
+			t(TraceEvent{Index: i, Name: name, Input: s, Output: result, Duration: time.Since(start), Err: serr})
+		}
+		if err != nil {
+			name, err := st.result(err)
+			return "", &PipeError{Index: i, Name: name, Err: err}
+		}
+		s = result
+	}
+	return s, nil
+}
+
+// result returns the name of st and its error err, unwrapping the name
+// given to Named.
+func (st Stage) result(err error) (string, error) {
+	var named *namedError
+	if errors.As(err, &named) {
+		err = named.err
+		if st.Name == "" {
+			return named.name, err
+		}
+	}
+	if st.Name == "" {
+		return funcName(st.Fn), err
+	}
+	return st.Name, err
+}
+
+// Lift adapts filter fn to PipeE. A panic in fn, such as ReplacePatternF's
+// on an invalid pattern, is returned as an error instead.
+func Lift(fn func(string) string) func(string) (string, error) {
+	return Named(funcName(fn), func(s string) (result string, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("%v", r)
+			}
+		}()
+		return fn(s), nil
+	})
+}
+
+// LiftAll lifts each of funcs with Lift.
+func LiftAll(funcs ...func(string) string) []func(string) (string, error) {
+	result := []func(string) (string, error){}
+	for _, fn := range funcs {
+		result = append(result, Lift(fn))
+	}
+	return result
+}
+
+// Named names fn so a *PipeError for its failure reports name.
+func Named(name string, fn func(string) (string, error)) func(string) (string, error) {
+	return func(s string) (string, error) {
+		result, err := fn(s)
+		if err != nil {
+			err = &namedError{name: name, err: err}
+		}
+		return result, err
+	}
+}
+
+// namedError carries the stage name from Named to PipeE and PipeStages.
+type namedError struct {
+	name string
+	err  error
+}
+
+func (e *namedError) Error() string {
+	return e.name + ": " + e.err.Error()
+}
+
+func (e *namedError) Unwrap() error {
+	return e.err
+}
+
+// funcName derives a readable name from a function value, turning
+// "github.com/mgutz/str.BetweenF.func1" into "BetweenF".
+func funcName(fn interface{}) string {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return "?"
+	}
+	name := f.Name()
+	name = name[strings.LastIndex(name, "/")+1:]
+	if i := strings.Index(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.TrimSuffix(name, "-fm")
+	for {
+		i := strings.LastIndex(name, ".")
+		if i < 0 || !strings.HasPrefix(name[i+1:], "func") {
+			return name
+		}
+		name = name[:i]
+	}
+}
+
+// PipelineError reports an invalid pipeline specification.
+type PipelineError struct {
+	// Offset is the byte offset of the error in the specification.
+	Offset int
+	Msg    string
+}
+
+func (e *PipelineError) Error() string {
+	return fmt.Sprintf("pipeline:%d: %s", e.Offset, e.Msg)
+}
+
+// ParsePipeline parses a specification of "|" separated filters, such as
+//
+//	clean | chompLeft "bc" | between "a" "f" | slugify
+//
+// into a Filter. Each stage is a filter name followed by its arguments, in
+// the same language and with the same filters as template placeholders,
+// including those added by RegisterFilter. An argument is a bare word, a
+// double quoted string with Go escapes or a single quoted raw string.
+// Integer arguments must be decimal. An empty specification yields a Filter
+// that returns its input unchanged. Errors are *PipelineError.
+func ParsePipeline(spec string) (Filter, error) {
+	tokens, err := tokenizePipeline(spec)
+	if err != nil {
+		return nil, newPipelineError(err)
+	}
+	if len(tokens) == 0 {
+		return Compose(), nil
+	}
+	filters, err := defaultFilters.compilePipeline(tokens, len(spec))
+	if err != nil {
+		return nil, newPipelineError(err)
+	}
+	// Name each stage by its source for tracing.
+	names := []string{}
+	start := tokens[0].offset
+	for _, token := range append(tokens, pipelineToken{offset: len(spec), pipe: true}) {
+		if token.pipe {
+			names = append(names, strings.TrimSpace(spec[start:token.offset]))
+			start = token.offset + 1
+		}
+	}
+	return func(s string) string {
+		return pipe(s, names, filters)
+	}, nil
+}
+
+func newPipelineError(err error) error {
+	serr := err.(*syntaxError)
+	return &PipelineError{Offset: serr.offset, Msg: serr.msg}
+}
+
+// MustParsePipeline is like ParsePipeline but panics if spec is invalid.
+func MustParsePipeline(spec string) Filter {
+	f, err := ParsePipeline(spec)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
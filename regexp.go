@@ -0,0 +1,116 @@
+package str
+
+import (
+	"container/list"
+	"regexp"
+	"strconv"
+	"sync"
+)
+
+// DefaultRegexpCacheSize is the number of compiled patterns cached by
+// default.
+const DefaultRegexpCacheSize = 256
+
+// regexpCache caches the patterns compiled by Match, ReplacePattern and the
+// other functions taking a pattern, evicting the least recently used.
+var regexpCache = &patternCache{
+	size:  DefaultRegexpCacheSize,
+	order: list.New(),
+	items: map[string]*list.Element{},
+}
+
+type patternCache struct {
+	sync.Mutex
+	size  int
+	order *list.List
+	items map[string]*list.Element
+}
+
+type patternEntry struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+// PatternMatch is a match of a pattern, as returned by MatchAll.
+type PatternMatch struct {
+	// Text is the matched text. Start and End are its byte offsets.
+	Text       string
+	Start, End int
+	// Groups holds the capture groups, keyed as by MatchGroups.
+	Groups map[string]string
+}
+
+// patternGroups maps the capture groups of a match of r in s, located by
+// loc, by number and by name.
+func patternGroups(r *regexp.Regexp, s string, loc []int) map[string]string {
+	groups := map[string]string{}
+	for i, name := range r.SubexpNames() {
+		value := ""
+		if loc[2*i] >= 0 {
+			value = s[loc[2*i]:loc[2*i+1]]
+		}
+		groups[strconv.Itoa(i)] = value
+		if name != "" {
+			groups[name] = value
+		}
+	}
+	return groups
+}
+
+// SetRegexpCacheSize sets the number of compiled patterns kept for reuse by
+// the functions taking a pattern. A size of 0 disables the cache.
+func SetRegexpCacheSize(n int) {
+	if n < 0 {
+		n = 0
+	}
+	regexpCache.Lock()
+	defer regexpCache.Unlock()
+	regexpCache.size = n
+	regexpCache.evict()
+}
+
+// compilePattern compiles pattern, reusing a cached Regexp if possible.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	c := regexpCache
+	c.Lock()
+	if e, ok := c.items[pattern]; ok {
+		c.order.MoveToFront(e)
+		c.Unlock()
+		return e.Value.(*patternEntry).re, nil
+	}
+	c.Unlock()
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	c.Lock()
+	defer c.Unlock()
+	if c.size > 0 {
+		if _, ok := c.items[pattern]; !ok {
+			c.items[pattern] = c.order.PushFront(&patternEntry{pattern, re})
+			c.evict()
+		}
+	}
+	return re, nil
+}
+
+// mustCompilePattern is like compilePattern but panics as regexp.MustCompile
+// does if pattern is invalid.
+func mustCompilePattern(pattern string) *regexp.Regexp {
+	re, err := compilePattern(pattern)
+	if err != nil {
+		panic(`regexp: Compile(` + strconv.Quote(pattern) + `): ` + err.Error())
+	}
+	return re
+}
+
+// evict removes the least recently used patterns until the cache fits its
+// size. The caller holds the lock.
+func (c *patternCache) evict() {
+	for c.order.Len() > c.size {
+		e := c.order.Back()
+		c.order.Remove(e)
+		delete(c.items, e.Value.(*patternEntry).pattern)
+	}
+}
@@ -1,9 +1,13 @@
 package str
 
 //import "testing"
+import "errors"
 import "fmt"
+import "io"
+import "strconv"
 
-//import "strings"
+import "strings"
+import "testing/iotest"
 
 func ExampleBetween() {
 	eg(1, Between("<a>foo</a>", "<a>", "</a>"))
@@ -37,20 +41,88 @@ func ExampleCamelize() {
 	eg(3, Camelize("-moz-something"))
 	eg(4, Camelize("_car_speed_"))
 	eg(5, Camelize("yes_we_can"))
+	eg(6, Camelize("XMLHttpRequest"))
+	eg(7, Camelize(Dasherize("backgroundColor")))
+	eg(8, Camelize("user_id"))
+	eg(9, Camelize("id_token"))
 	// Output:
 	// 1: dataRate
 	// 2: backgroundColor
 	// 3: MozSomething
 	// 4: CarSpeed
 	// 5: yesWeCan
+	// 6: XMLHTTPRequest
+	// 7: backgroundColor
+	// 8: userID
+	// 9: idToken
+}
+
+func ExampleCamelize_unicode() {
+	eg(1, Camelize("émile_zola"))
+	eg(2, Classify("émile_zola"))
+	eg(3, Dasherize("ÉmileZola"))
+	eg(4, Underscore("ΟΔΟΣ_ΑΘΗΝΩΝ"))
+	eg(5, Camelize("дата_рождения"))
+	eg(6, Humanize("привет_мир"))
+	eg(7, Classify("ijeoma_okafor"))
+	eg(8, Underscore("IJsselMeer"))
+	eg(9, Classify("straße_name"))
+	eg(10, Dasherize("東京タワー_見学"))
+	eg(11, Classify("ǆungla_ǳ"))
+	// Output:
+	// 1: émileZola
+	// 2: ÉmileZola
+	// 3: -émile-zola
+	// 4: _οδος_αθηνων
+	// 5: датаРождения
+	// 6: Привет мир
+	// 7: IjeomaOkafor
+	// 8: _i_jssel_meer
+	// 9: StraßeName
+	// 10: 東京タワー-見学
+	// 11: ǅunglaǲ
 }
 
 func ExampleCapitalize() {
 	eg(1, Capitalize("abc"))
 	eg(2, Capitalize("ABC"))
+	eg(3, Capitalize(""))
+	eg(4, Capitalize("émile"))
+	eg(5, Capitalize("ПРИВЕТ"))
+	eg(6, Capitalize("ΟΔΟΣ"))
+	eg(7, Capitalize("ǆungla"))
+	eg(8, Capitalize("ijeoma"))
+	eg(9, Capitalize("ßa"))
+	eg(10, Capitalize("ﬁsh"))
+	eg(11, Capitalize("日本語"))
 	// Output:
 	// 1: Abc
 	// 2: Abc
+	// 3:
+	// 4: Émile
+	// 5: Привет
+	// 6: Οδος
+	// 7: ǅungla
+	// 8: Ijeoma
+	// 9: Ssa
+	// 10: Fish
+	// 11: 日本語
+}
+
+func ExampleCapitalizeLocale() {
+	eg(1, CapitalizeLocale("istanbul", "tr"))
+	eg(2, CapitalizeLocale("istanbul", "en"))
+	eg(3, CapitalizeLocale("IĞDIR", "tr-TR"))
+	eg(4, CapitalizeLocale("ijssel", "nl"))
+	eg(5, CapitalizeLocale("ijssel", "tr"))
+	fmt.Printf("6: %+q\n", CapitalizeLocale("i\u0307\u0301s", "lt"))
+	// Output:
+	// 1: İstanbul
+	// 2: Istanbul
+	// 3: Iğdır
+	// 4: IJssel
+	// 5: İjssel
+	// 6: "I\u0301s"
 }
 
 func ExampleCharAt() {
@@ -75,6 +147,28 @@ func ExampleCharAtF() {
 	// 1: b
 }
 
+func ExampleCharAtGraphemes() {
+	eg(1, CharAtGraphemes("he\u0301llo", 1))
+	eg(2, CharAtGraphemes("a\U0001F1EF\U0001F1F5b", 1))
+	eg(3, CharAtGraphemes("abc", 10))
+	// Output:
+	// 1: é
+	// 2: 🇯🇵
+	// 3:
+}
+
+func ExampleCharAtRunes() {
+	eg(1, CharAtRunes("héllo", 1))
+	eg(2, CharAtRunes("中文", 1))
+	eg(3, CharAtRunes("abc", -1))
+	eg(4, Pipe("héllo", CharAtRunesF(1)))
+	// Output:
+	// 1: é
+	// 2: 文
+	// 3:
+	// 4: é
+}
+
 func ExampleChompLeft() {
 	eg(1, ChompLeft("foobar", "foo"))
 	eg(2, ChompLeft("foobar", "bar"))
@@ -119,12 +213,24 @@ func ExampleClassify() {
 	eg(3, Classify("-moz-something"))
 	eg(4, Classify("_car_speed_"))
 	eg(5, Classify("yes_we_can"))
+	eg(6, Classify("http_server"))
+	eg(7, Classify(Underscore("APIKey")))
+	eg(8, Classify(Underscore(Classify("xml_http_request"))))
+	eg(9, Classify(Underscore("UserIDURL")))
+	eg(10, Underscore(Camelize("XMLHttpRequest")))
+	eg(11, Camelize(Dasherize("jsonAPIKey")))
 	// Output:
 	// 1: DataRate
 	// 2: BackgroundColor
 	// 3: MozSomething
 	// 4: CarSpeed
 	// 5: YesWeCan
+	// 6: HTTPServer
+	// 7: APIKey
+	// 8: XMLHTTPRequest
+	// 9: UserIDURL
+	// 10: _xml_http_request
+	// 11: jsonAPIKey
 }
 
 func ExampleClean() {
@@ -137,18 +243,119 @@ func ExampleClean() {
 	// 3: please clean me
 }
 
+func ExampleCompileTemplate() {
+	t, _ := CompileTemplate("Hello {{name}}, {{ greeting }}", TemplateOptions{})
+	s, err := t.Render(map[string]interface{}{"name": "foo", "greeting": "{{name}}"})
+	eg(1, s)
+	eg(2, err)
+	s, _ = t.Render(map[string]interface{}{"name": "bar"})
+	eg(3, s)
+
+	t = MustCompileTemplate("Hello <%name%>", TemplateOptions{Opening: "<%", Closing: "%>", Missing: MissingKeyEmpty})
+	s, _ = t.Render(nil)
+	eg(4, s+"!")
+
+	t = MustCompileTemplate("Hello\n  {{name}}", TemplateOptions{Missing: MissingKeyError})
+	_, err = t.Render(nil)
+	eg(5, err)
+
+	_, err = CompileTemplate("a\nb {{name", TemplateOptions{})
+	eg(6, err)
+	_, err = CompileTemplate("{{ }}", TemplateOptions{})
+	eg(7, err)
+	terr := err.(*TemplateError)
+	eg(8, terr.Offset)
+	// Output:
+	// 1: Hello foo, {{name}}
+	// 2: <nil>
+	// 3: Hello bar, {{ greeting }}
+	// 4: Hello !
+	// 5: template:2:3: no value for key "name"
+	// 6: template:2:3: unclosed placeholder, expected "}}"
+	// 7: template:1:1: empty placeholder
+	// 8: 0
+}
+
+func ExampleCompileTemplate_filters() {
+	RegisterFilter("shout", func(s string) string {
+		return strings.ToUpper(s) + "!"
+	})
+	t := MustCompileTemplate(`{{name | trim | slugify}} {{title | truncate 12 "…"}} {{n | padLeft "0" 4}} {{name | trim | shout}}`, TemplateOptions{})
+	s, _ := t.Render(map[string]interface{}{"name": "  Hello World ", "title": "The quick brown fox", "n": 42})
+	eg(1, s)
+
+	_, err := CompileTemplate("a {{name | nope}}", TemplateOptions{})
+	eg(2, err)
+	_, err = CompileTemplate("{{name | truncate}}", TemplateOptions{})
+	eg(3, err)
+	_, err = CompileTemplate("{{name | truncate x}}", TemplateOptions{})
+	eg(4, err)
+	_, err = CompileTemplate("{{name | trim |}}", TemplateOptions{})
+	eg(5, err)
+	_, err = CompileTemplate(`{{name | padLeft "0}}`, TemplateOptions{})
+	eg(6, err)
+	eg(7, Template("{{a|b}} {{ a | upper }}", map[string]interface{}{"a": "x", "a|b": "y"}))
+	// Output:
+	// 1: hello-world The quick… 0042 HELLO WORLD!
+	// 2: template:1:12: unknown filter "nope"
+	// 3: template:1:10: truncate expects 1 to 2 arguments, got 0
+	// 4: template:1:19: truncate expects an integer, got "x"
+	// 5: template:1:16: missing filter name
+	// 6: template:1:18: unterminated quoted string
+	// 7: y X
+}
+
+func ExampleCompose() {
+	normalize := Compose(Clean, strings.ToLower, ReplaceF(" ", "-", -1))
+	eg(1, normalize("  Hello   World "))
+	eg(2, Pipe("  Hello ", normalize.Then(EnsurePrefixF("#"))))
+	// Output:
+	// 1: hello-world
+	// 2: #hello
+}
+
+func ExampleConvertCase() {
+	eg(1, ConvertCase("user_id", CaseCamel))
+	eg(2, ConvertCase("content-type", CaseTrain))
+	eg(3, ConvertCase("maxRetryCount", CaseScreamingSnake))
+	eg(4, ConvertCase("Max Retry Count", CaseDot))
+	eg(5, ConvertCase("api.v2.users", CasePath))
+	eg(6, ConvertCase("parseHTTPRequest", CaseSentence))
+	eg(7, ConvertCase("parse_http_request", CaseTitle))
+	eg(8, ConvertCase("Data-Rate", CaseFlat))
+	eg(9, NewCaseConverter("GraphQL").Convert("graphql_schema", CasePascal))
+	eg(10, Pipe("DATA_RATE", ConvertCaseF(CaseKebab)))
+	eg(11, Template("{{name | convertCase screamingSnake}}", map[string]string{"name": "dataRate"}))
+	// Output:
+	// 1: userID
+	// 2: Content-Type
+	// 3: MAX_RETRY_COUNT
+	// 4: max.retry.count
+	// 5: api/v2/users
+	// 6: Parse HTTP request
+	// 7: Parse HTTP Request
+	// 8: datarate
+	// 9: GraphQLSchema
+	// 10: data-rate
+	// 11: DATA_RATE
+}
+
 func ExampleDasherize() {
 	eg(1, Dasherize("dataRate"))
 	eg(2, Dasherize("CarSpeed"))
 	eg(3, Dasherize("yesWeCan"))
 	eg(4, Dasherize(""))
 	eg(5, Dasherize("ABC"))
+	eg(6, Dasherize("XMLHttpRequest"))
+	eg(7, Dasherize("user_id 2"))
 	// Output:
 	// 1: data-rate
 	// 2: -car-speed
 	// 3: yes-we-can
 	// 4:
-	// 5: -a-b-c
+	// 5: -abc
+	// 6: -xml-http-request
+	// 7: user-id-2
 }
 
 func ExampleDecodeHTMLEntities() {
@@ -161,6 +368,57 @@ func ExampleDecodeHTMLEntities() {
 	// 3: http://
 }
 
+func ExampleDetectCase() {
+	for _, s := range []string{
+		"dataRate", "DataRate", "data_rate", "DATA_RATE", "data-rate",
+		"Data-Rate", "data.rate", "data/rate", "The Lord of the Rings",
+		"Launch of the NASA rocket", "datarate", "data_Rate", "Hello World again",
+	} {
+		fmt.Println(s, DetectCase(s))
+	}
+	// Output:
+	// dataRate camel
+	// DataRate pascal
+	// data_rate snake
+	// DATA_RATE screamingSnake
+	// data-rate kebab
+	// Data-Rate train
+	// data.rate dot
+	// data/rate path
+	// The Lord of the Rings title
+	// Launch of the NASA rocket sentence
+	// datarate flat
+	// data_Rate mixed
+	// Hello World again mixed
+}
+
+func ExampleDetectCase_roundTrip() {
+	for c := CaseCamel; c <= CaseFlat; c++ {
+		s := ConvertCase("parse http request id", c)
+		fmt.Println(s, DetectCase(s) == c, ConvertCase(s, c) == s)
+	}
+	eg(1, DetectCase(ConvertCase("user id", CaseTitle)))
+	eg(2, DetectCase(ConvertCase("user id", CaseSentence)))
+	eg(3, DetectCase("Launch of the NASA Rocket"))
+	eg(4, ConvertCase(ConvertCase("xml http request", CasePascal), CaseSnake))
+	// Output:
+	// parseHTTPRequestID true true
+	// ParseHTTPRequestID true true
+	// parse_http_request_id true true
+	// PARSE_HTTP_REQUEST_ID true true
+	// parse-http-request-id true true
+	// Parse-HTTP-Request-ID true true
+	// parse.http.request.id true true
+	// parse/http/request/id true true
+	// Parse HTTP Request ID true true
+	// Parse HTTP request ID true true
+	// parsehttprequestid true true
+	// 1: title
+	// 2: title
+	// 3: title
+	// 4: xml_http_request
+}
+
 func ExampleEnsurePrefix() {
 	eg(1, EnsurePrefix("foobar", "foo"))
 	eg(2, EnsurePrefix("bar", "foo"))
@@ -195,14 +453,67 @@ func ExampleEnsureSuffix() {
 	// 5: bar
 }
 
+func ExampleFilterLines() {
+	var b strings.Builder
+	err := FilterLines(&b, strings.NewReader("  one  \r\ntwo\n\n three"), Compose(Clean, strings.ToUpper))
+	fmt.Printf("%q %v\n", b.String(), err)
+
+	b.Reset()
+	err = FilterLines(&b, strings.NewReader("a\nb\n"), Reverse)
+	fmt.Printf("%q %v\n", b.String(), err)
+	// Output:
+	// "ONE\r\nTWO\n\nTHREE" <nil>
+	// "a\nb\n" <nil>
+}
+
+func ExampleFilterNames() {
+	names := FilterNames()
+	eg(1, names[0])
+	eg(2, SliceContains(names, "slugify"))
+	eg(3, SliceContains(names, "Slugify"))
+	// Output:
+	// 1: between
+	// 2: true
+	// 3: false
+}
+
+func ExampleFilterRecords() {
+	var b strings.Builder
+	err := FilterRecords(&b, strings.NewReader("a-b;;c-d;;e"), ReplaceF("-", "+", -1), RecordOptions{Separator: ";;"})
+	fmt.Printf("%q %v\n", b.String(), err)
+
+	b.Reset()
+	err = FilterRecords(&b, strings.NewReader("short\nmuch too long\n"), strings.ToUpper, RecordOptions{MaxRecord: 8})
+	fmt.Printf("%q %v\n", b.String(), err)
+	// Output:
+	// "a+b;;c+d;;e" <nil>
+	// "SHORT\n" str: record too long
+}
+
+func ExampleGraphemes() {
+	eg(1, QuoteItems(Graphemes("e\u0301a")))
+	eg(2, len(Graphemes("\U0001F468\u200D\U0001F469\u200D\U0001F467!")))
+	eg(3, len(Graphemes("\U0001F1FA\U0001F1F8\U0001F1EB\U0001F1F7")))
+	eg(4, len(Graphemes("\r\n한국어")))
+	// Output:
+	// 1: ["é" "a"]
+	// 2: 2
+	// 3: 2
+	// 4: 4
+}
+
 func ExampleHumanize() {
 	eg(1, Humanize("the_humanize_string_method"))
 	eg(2, Humanize("ThehumanizeStringMethod"))
 	eg(3, Humanize("the humanize string method"))
+	eg(4, Humanize("author_id"))
+	eg(5, Humanize("XMLHttpRequest"))
 	// Output:
 	// 1: The humanize string method
 	// 2: Thehumanize string method
 	// 3: The humanize string method
+	// 4: Author
+	// 5: Xml http request
 }
 
 func ExampleIif() {
@@ -300,6 +611,8 @@ func ExampleIsLower() {
 	eg(7, IsLower("ÁÉÍÓÚÃÕÀÈÌÒÙÂÊÎÔÛÄËÏÖÜÇ"))
 	eg(8, IsLower("áéúóúãõàèìòùâêîôûäëïöüçÁ"))
 	eg(9, IsLower("áéúóúãõàèìòùâêîôû äëïöüç"))
+	eg(10, IsLower("οδός"))
+	eg(11, IsLower("ǅ"))
 	// Output:
 	// 1: true
 	// 2: false
@@ -310,6 +623,8 @@ func ExampleIsLower() {
 	// 7: false
 	// 8: false
 	// 9: false
+	// 10: true
+	// 11: false
 }
 
 func ExampleIsNumeric() {
@@ -344,6 +659,8 @@ func ExampleIsUpper() {
 	eg(7, IsUpper("ÁÉÍÓÚÃÕÀÈÌÒÙÂÊÎÔÛÄËÏÖÜÇ"))
 	eg(8, IsUpper("áéúóúãõàèìòùâêîôûäëïöüçÁ"))
 	eg(9, IsUpper("ÁÉÍÓÚÃÕÀÈÌÒÙÂÊÎ ÔÛÄËÏÖÜÇ"))
+	eg(10, IsUpper("ПРИВЕТ"))
+	eg(11, IsUpper("STRAß"))
 	// Output:
 	// 1: false
 	// 2: true
@@ -354,6 +671,37 @@ func ExampleIsUpper() {
 	// 7: true
 	// 8: false
 	// 9: false
+	// 10: true
+	// 11: false
+}
+
+func ExampleJoinArgv() {
+	argv := []string{"C:\\Program Files\\app.exe", "", "a b", `it's`, `say "hi"`, `C:\dir\`, `a\\"b`,
+		"tab\tnew\nline", "$HOME", "#x", "~", "50%", "a&b|c", "^", `\`, "é 中文"}
+	dialects := map[ArgvDialect]string{ArgvLegacy: "legacy", ArgvPOSIX: "posix", ArgvWindows: "windows", ArgvCmd: "cmd"}
+	for _, dialect := range []ArgvDialect{ArgvLegacy, ArgvPOSIX, ArgvWindows, ArgvCmd} {
+		parsed, err := ParseArgvWith(JoinArgv(argv, dialect), ArgvOptions{Dialect: dialect})
+		ok := err == nil && len(parsed) == len(argv)
+		for i := 0; ok && i < len(argv); i++ {
+			ok = parsed[i] == argv[i]
+		}
+		fmt.Println(dialects[dialect], ok)
+	}
+	eg(1, JoinArgv([]string{"ls", "-l", "my file", "it's"}, ArgvPOSIX))
+	eg(2, JoinArgv([]string{`C:\Program Files\app.exe`, `C:\dir\`, `say "hi"`, "x"}, ArgvWindows))
+	eg(3, JoinArgv([]string{"echo", "a&b", `"q"`}, ArgvCmd))
+	eg(4, QuoteItems(QuoteArgv([]string{"a b", `c"d`}, ArgvLegacyWindows)))
+	eg(5, QuoteArg("a b", ArgvLegacy))
+	// Output:
+	// legacy true
+	// posix true
+	// windows true
+	// cmd true
+	// 1: ls -l 'my file' 'it'\''s'
+	// 2: "C:\Program Files\app.exe" C:\dir\ "say \"hi\"" x
+	// 3: echo a^&b ^"\^"q\^"^"
+	// 4: ["'a b'" "'c\"d'"]
+	// 5: a\ b
 }
 
 func ExampleLeft() {
@@ -368,6 +716,28 @@ func ExampleLeft() {
 	// 4: ef
 }
 
+func ExampleLeftGraphemes() {
+	eg(1, LeftGraphemes("he\u0301llo", 2))
+	eg(2, LeftGraphemes("he\u0301llo", -2))
+	eg(3, Pipe("\U0001F44D\U0001F3FDok", LeftGraphemesF(1)))
+	// Output:
+	// 1: hé
+	// 2: lo
+	// 3: 👍🏽
+}
+
+func ExampleLeftRunes() {
+	eg(1, LeftRunes("héllo", 2))
+	eg(2, LeftRunes("héllo", -2))
+	eg(3, LeftRunes("héllo", 100))
+	eg(4, Pipe("héllo", LeftRunesF(2)))
+	// Output:
+	// 1: hé
+	// 2: lo
+	// 3: héllo
+	// 4: hé
+}
+
 func ExampleLeftOf() {
 	eg(1, LeftOf("abcdef", "def"))
 	eg(2, LeftOf("abcdef", "abc"))
@@ -400,6 +770,266 @@ func ExampleMatch() {
 	// 3: false
 }
 
+func ExampleMatchAll() {
+	for _, m := range MatchAll("x=1, y=22", `(?P<key>\w+)=(\d+)`) {
+		eg(m.Start, fmt.Sprint(m.Text, " ", m.End, " ", m.Groups))
+	}
+	eg(10, len(MatchAll("abc", `\d`)))
+	eg(11, Pipe("x=1, y=22", MatchAllF(`\d+`, "+")))
+	eg(12, Pipe("abc", MatchAllF(`\d`, ",")) == "")
+	eg(13, Template("{{tags | matchAll '#\\w+' ' '}}", map[string]string{"tags": "see #go and #regexp"}))
+	// Output:
+	// 0: x=1 3 map[0:x=1 1:x 2:1 key:x]
+	// 5: y=22 9 map[0:y=22 1:y 2:22 key:y]
+	// 10: 0
+	// 11: 1+22
+	// 12: true
+	// 13: #go #regexp
+}
+
+func ExampleMatchE() {
+	ok, err := MatchE("foobar", `^fo.*r$`)
+	eg(1, ok)
+	eg(2, err)
+	ok, err = MatchE("foobar", `^fo(.*r$`)
+	eg(3, ok)
+	eg(4, err)
+	// Output:
+	// 1: true
+	// 2: <nil>
+	// 3: false
+	// 4: error parsing regexp: missing closing ): `^fo(.*r$`
+}
+
+func ExampleMatchGroup() {
+	eg(1, MatchGroup("me@example.com", `(?P<user>\w+)@(\S+)`, "user"))
+	eg(2, MatchGroup("me@example.com", `(?P<user>\w+)@(\S+)`, "2"))
+	eg(3, MatchGroup("nobody", `(?P<user>\w+)@`, "user") == "")
+	eg(4, Pipe("v1.22.3", MatchGroupF(`v\d+\.(\d+)`, "1")))
+	eg(5, Template("{{email | matchGroup '@(.+)' 1}}", map[string]string{"email": "me@example.com"}))
+	// Output:
+	// 1: me
+	// 2: example.com
+	// 3: true
+	// 4: 22
+	// 5: example.com
+}
+
+func ExampleMatchGroups() {
+	date := MatchGroups("due 2024-03-15", `(?P<year>\d{4})-(?P<month>\d\d)-(?P<day>\d\d)`)
+	eg(1, date["year"]+"/"+date["month"]+"/"+date["day"])
+	eg(2, MatchGroups("v1", `v(\d+)(?:\.(\d+))?`))
+	eg(3, MatchGroups("none", `\d+`) == nil)
+	// Output:
+	// 1: 2024/03/15
+	// 2: map[0:v1 1:1 2:]
+	// 3: true
+}
+
+func ExampleNewCaseConverter() {
+	c := NewCaseConverter(append(CommonInitialisms(), "GraphQL", "OAuth")...)
+	eg(1, c.Classify("graphql_oauth_url"))
+	eg(2, c.Underscore("GraphQLSchema"))
+	eg(3, c.Dasherize("newOAuthToken"))
+	eg(4, c.Camelize("graphql_id"))
+
+	none := NewCaseConverter()
+	eg(5, none.Camelize("user_id"))
+	eg(6, none.Classify("http_server"))
+	// Output:
+	// 1: GraphQLOAuthURL
+	// 2: _graphql_schema
+	// 3: new-oauth-token
+	// 4: graphqlID
+	// 5: userId
+	// 6: HttpServer
+}
+
+func ExampleNewCaseConverterLocale() {
+	nl := NewCaseConverterLocale("nl", CommonInitialisms()...)
+	eg(1, nl.Classify("ijssel_meer"))
+	eg(2, nl.Underscore("IJsselMeer"))
+	eg(3, nl.Convert("ijssel meer id", CaseTitle))
+	eg(4, Classify("ijssel_meer"))
+
+	tr := NewCaseConverterLocale("tr")
+	eg(5, tr.Classify("istanbul_ili"))
+	eg(6, tr.Convert("istanbul ili", CaseScreamingSnake))
+	eg(7, tr.Dasherize("İstanbulİli"))
+	// Output:
+	// 1: IJsselMeer
+	// 2: _ijssel_meer
+	// 3: IJssel Meer ID
+	// 4: IjsselMeer
+	// 5: İstanbulİli
+	// 6: İSTANBUL_İLİ
+	// 7: -istanbul-ili
+}
+
+func ExampleNewChunkReader() {
+	html := "<p>Hello <b>wor</b>ld</p>\n<p class=\"x\">bye</p>"
+	r := NewChunkReader(iotest.OneByteReader(strings.NewReader(html)), StripTagsChunks())
+	var b strings.Builder
+	_, err := io.Copy(&b, r)
+	fmt.Printf("%q %v\n", b.String(), err)
+	// Output:
+	// "Hello world\nbye" <nil>
+}
+
+func ExampleNewChunkWriter() {
+	var b strings.Builder
+	w := NewChunkWriter(&b, CleanChunks())
+	chunks := []string{"  hel", "lo  ", " ", "\n\nbig  w", "ide", "\u00a0world  "}
+	for _, chunk := range chunks {
+		w.Write([]byte(chunk))
+	}
+	err := w.Close()
+	fmt.Printf("%q %v\n", b.String(), err)
+	fmt.Printf("%q\n", Clean(strings.Join(chunks, "")))
+	_, err = w.Write([]byte("more"))
+	fmt.Println(err)
+	// Output:
+	// "hello big wide world" <nil>
+	// "hello big wide world"
+	// str: write to closed chunk writer
+}
+
+func ExampleOnLines() {
+	eg(1, QuoteItems([]string{OnLines(strings.TrimSpace)(" a \r\n b\n")}))
+	eg(2, OnLines(PadLeftF(".", 3))("a\nbb"))
+	// Output:
+	// 1: ["a\nb\n"]
+	// 2: ..a .bb
+}
+
+func ExampleOnMatches() {
+	eg(1, OnMatches(`\d+`, PadLeftF("0", 3))("v1.22.3"))
+	eg(2, OnMatches(`[a-z]+@`, strings.ToUpper)("mail foo@example.com"))
+	// Output:
+	// 1: v001.022.003
+	// 2: mail FOO@example.com
+}
+
+func ExampleOnWords() {
+	eg(1, OnWords(Capitalize)("hello  big\tWORLD ") == "Hello  Big\tWorld ")
+	eg(2, OnWords(Reverse)("ab cd"))
+	fmt.Printf("3: %q\n", OnWords(EnsurePrefixF("#"))("  a b "))
+	// Output:
+	// 1: true
+	// 2: ba dc
+	// 3: "  #a #b "
+}
+
+func ExampleParseArgv() {
+	argv, err := ParseArgv(`gosu --test="some arg" -w`)
+	eg(1, QuoteItems(argv))
+	eg(2, err)
+	_, err = ParseArgv(`echo "unterminated`)
+	eg(3, err)
+	eg(4, err.(*ArgvError).Err == ErrUnterminatedQuote)
+	_, err = parseArgvLegacy(`echo foo\`, false)
+	eg(5, err)
+	argv, _ = parseArgvLegacy(`dir C:\foo\`, true)
+	eg(6, QuoteItems(argv))
+	// Output:
+	// 1: ["gosu" "--test=some arg" "-w"]
+	// 2: <nil>
+	// 3: argv:5: starting quote has no ending quote
+	// 4: true
+	// 5: argv:8: escape character at end of string
+	// 6: ["dir" "C:\\foo\\"]
+}
+
+func ExampleParseArgvWith() {
+	posix := func(s string) interface{} {
+		argv, err := ParseArgvWith(s, ArgvOptions{Dialect: ArgvPOSIX})
+		if err != nil {
+			return err
+		}
+		return QuoteItems(argv)
+	}
+	eg(1, posix(`cp 'a b'  "c \"d\" \$e \x" f\ g ''`))
+	eg(2, posix("make all \\\n  install # done"))
+	eg(3, posix(`printf $'a\tb\x41\u00e9\'\101' end`))
+	eg(4, posix(`echo "a\`))
+	eg(5, posix(`echo a\`))
+
+	windows := func(s string) []string {
+		argv, _ := ParseArgvWith(s, ArgvOptions{Dialect: ArgvWindows})
+		return QuoteItems(argv)
+	}
+	eg(6, windows(`"C:\Program Files\app.exe" a\\b "c d" e\"f g\\\\"h i"`))
+	eg(7, windows(`app "a \"quoted\" word" "x""y C:\dir\ "trailing\\"`))
+	eg(8, windows(`  app  `))
+	// Output:
+	// 1: ["cp" "a b" "c \"d\" $e \\x" "f g" ""]
+	// 2: ["make" "all" "install"]
+	// 3: ["printf" "a\tbAé'A" "end"]
+	// 4: argv:5: starting quote has no ending quote
+	// 5: argv:6: escape character at end of string
+	// 6: ["C:\\Program Files\\app.exe" "a\\\\b" "c d" "e\"f" "g\\\\h i"]
+	// 7: ["app" "a \"quoted\" word" "x\"y" "C:\\dir\\" "trailing\\"]
+	// 8: ["app"]
+}
+
+func ExampleParseArgvWith_expansion() {
+	env := map[string]string{"HOME": "/home/me", "NAME": "world", "EMPTY": "", "FLAGS": "-a\t-b"}
+	opts := ArgvOptions{
+		Dialect: ArgvPOSIX,
+		Lookup: func(name string) (string, bool) {
+			v, ok := env[name]
+			return v, ok
+		},
+	}
+	expand := func(s string) interface{} {
+		argv, err := ParseArgvWith(s, opts)
+		if err != nil {
+			return err
+		}
+		return QuoteItems(argv)
+	}
+	eg(1, expand(`echo $NAME "$NAME" '$NAME' \$NAME ${NAME}s $`))
+	eg(2, expand(`ls ~ ~/src a~ "~" $FLAGS "$FLAGS" $EMPTY x`))
+	eg(3, expand(`echo ${MISSING:-default} ${EMPTY:-empty} ${EMPTY-unset} ${NAME:+set} ${MISSING:+set}`))
+	eg(4, expand(`echo ${MISSING:-${NAME}!}`))
+	eg(5, expand(`echo ${MISSING:?must be set}`))
+	eg(6, expand(`echo ${EMPTY:?}`))
+	eg(7, expand(`echo ${NAME`))
+	eg(8, expand(`echo ${NAME:x}`))
+	opts.NoUnset = true
+	eg(9, expand(`echo $MISSING`))
+	_, err := ParseArgvWith(`echo $MISSING`, opts)
+	eg(10, errors.Is(err, ErrUndefinedVariable))
+
+	opts.Glob = func(pattern string) ([]string, error) {
+		if pattern == `*.go` {
+			return []string{"a.go", "b.go"}, nil
+		}
+		return nil, nil
+	}
+	eg(11, expand(`ls *.go "*.go" *.txt \*.go`))
+	_, err = ParseArgvWith(`ls $HOME`, ArgvOptions{Lookup: opts.Lookup})
+	eg(12, err)
+	eg(13, errors.Is(err, ErrExpansionDialect))
+	opts.Glob = nil
+	eg(14, expand(`echo ${NAME:-${MISSING:?unused}} ${MISSING:+$MISSING} ${NAME:?$MISSING}`))
+	// Output:
+	// 1: ["echo" "world" "world" "$NAME" "$NAME" "worlds" "$"]
+	// 2: ["ls" "/home/me" "/home/me/src" "a~" "~" "-a" "-b" "-a\t-b" "x"]
+	// 3: ["echo" "default" "empty" "set"]
+	// 4: ["echo" "world!"]
+	// 5: argv:5: undefined variable MISSING: must be set
+	// 6: argv:5: undefined variable EMPTY: parameter null or not set
+	// 7: argv:5: ${ has no closing brace
+	// 8: argv:5: bad substitution
+	// 9: argv:5: undefined variable MISSING
+	// 10: true
+	// 11: ["ls" "a.go" "b.go" "*.go" "*.txt" "*.go"]
+	// 12: argv:0: expansion requires ArgvPOSIX
+	// 13: true
+	// 14: ["echo" "world" "world"]
+}
+
 func ExamplePad() {
 	eg(1, Pad("hello", "x", 5))
 	eg(2, Pad("hello", "x", 10))
@@ -414,6 +1044,77 @@ func ExamplePad() {
 	// 5: hello
 }
 
+func ExamplePadWidth() {
+	eg(1, PadWidth("中文", "x", 6))
+	eg(2, PadWidth("中文", "x", 7))
+	eg(3, PadWidth("héllo", "-=", 10))
+	eg(4, PadWidth("ab", "中", 5) == "中ab ")
+	eg(5, Pipe("中文", PadWidthF("*", 4)))
+	// Output:
+	// 1: x中文x
+	// 2: xx中文x
+	// 3: -=-héllo-=
+	// 4: true
+	// 5: 中文
+}
+
+func ExamplePadWidthWith() {
+	wide := WidthOptions{AmbiguousIsWide: true}
+	eg(1, PadWidthWith("αβ", "x", 6, wide))
+	eg(2, PadWidth("αβ", "x", 6))
+	eg(3, Pipe("αβ", PadWidthWithF("*", 5, wide)))
+	// Output:
+	// 1: xαβx
+	// 2: xxαβxx
+	// 3: *αβ
+}
+
+func ExamplePadLeftWidth() {
+	eg(1, PadLeftWidth("中文", ".", 6))
+	eg(2, PadLeftWidth("\x1b[1mab\x1b[0m", ".", 4) == "..\x1b[1mab\x1b[0m")
+	eg(3, PadLeftWidth("ab", "12345", 5))
+	eg(4, Pipe("中文", PadLeftWidthF(".", 5)))
+	// Output:
+	// 1: ..中文
+	// 2: true
+	// 3: 123ab
+	// 4: .中文
+}
+
+func ExamplePadLeftWidthWith() {
+	wide := WidthOptions{AmbiguousIsWide: true}
+	eg(1, PadLeftWidthWith("αβ", ".", 6, wide))
+	eg(2, PadLeftWidthWith("ab", "α", 5, wide) == "α ab")
+	eg(3, Pipe("αβ", PadLeftWidthWithF(".", 5, wide)))
+	// Output:
+	// 1: ..αβ
+	// 2: true
+	// 3: .αβ
+}
+
+func ExamplePadRightWidth() {
+	eg(1, PadRightWidth("e\u0301", ".", 3) == "e\u0301..")
+	eg(2, PadRightWidth("\U0001F44D", ".", 3))
+	eg(3, PadRightWidth("abcdef", ".", 3))
+	eg(4, Pipe("中文", PadRightWidthF("ab", 7)))
+	// Output:
+	// 1: true
+	// 2: 👍.
+	// 3: abcdef
+	// 4: 中文aba
+}
+
+func ExamplePadRightWidthWith() {
+	wide := WidthOptions{AmbiguousIsWide: true}
+	eg(1, PadRightWidthWith("αβ", ".", 6, wide))
+	eg(2, PadRightWidthWith("αβ", ".", 4, wide))
+	eg(3, Pipe("ab", PadRightWidthWithF("α", 6, wide)))
+	// Output:
+	// 1: αβ..
+	// 2: αβ
+	// 3: abαα
+}
+
 func ExamplePadLeft() {
 	eg(1, PadLeft("hello", "x", 5))
 	eg(2, PadLeft("hello", "x", 10))
@@ -442,12 +1143,94 @@ func ExamplePadRight() {
 	// 5: hello
 }
 
+func ExampleParsePipeline() {
+	normalize, err := ParsePipeline(`clean | chompLeft "bc" | between "a" "f" | slugify`)
+	eg(1, normalize("\nabcdef   \n"))
+	eg(2, err)
+	eg(3, MustParsePipeline(`trim | truncate 8 '~' | wrapHTML "a" href "#top"`)("  a long title  "))
+	eg(4, MustParsePipeline(`replace "\t" "->" | padLeft . 6`)("a\tb"))
+	eg(5, MustParsePipeline(``)("unchanged"))
+
+	parse := func(spec string) error {
+		_, err := ParsePipeline(spec)
+		return err
+	}
+	eg(6, parse(`clean | frobnicate`))
+	eg(7, parse(`clean | between "a"`))
+	eg(8, parse(`left 3 | substr 1 x`))
+	eg(9, parse(`clean || slugify`))
+	eg(10, parse(`ensurePrefix "/`))
+	eg(11, parse(`clean |`))
+	eg(12, parse(`clean | truncate 3 "..." extra`).(*PipelineError).Offset)
+	// Output:
+	// 1: bcde
+	// 2: <nil>
+	// 3: <a href="#top">a long~</a>
+	// 4: ..a->b
+	// 5: unchanged
+	// 6: pipeline:8: unknown filter "frobnicate"
+	// 7: pipeline:8: between expects 2 arguments, got 1
+	// 8: pipeline:18: substr expects an integer, got "x"
+	// 9: pipeline:7: missing filter name
+	// 10: pipeline:13: unterminated quoted string
+	// 11: pipeline:7: missing filter name
+	// 12: 8
+}
+
 func ExamplePipe() {
 	eg(1, Pipe("\nabcdef   \n", Clean, BetweenF("a", "f"), ChompLeftF("bc")))
 	// Output:
 	// 1: de
 }
 
+func ExamplePipeE() {
+	nonEmpty := func(s string) (string, error) {
+		if s == "" {
+			return "", errors.New("empty input")
+		}
+		return s, nil
+	}
+	s, err := PipeE("  <b>Hello</b>  ", Lift(Clean), Lift(BetweenF("<b>", "</b>")), nonEmpty, Lift(Slugify))
+	eg(1, s)
+	eg(2, err)
+
+	_, err = PipeE("<b></b>", Lift(BetweenF("<b>", "</b>")), Named("required", nonEmpty), Lift(Slugify))
+	eg(3, err)
+	_, err = PipeE("abc", Lift(Clean), Lift(ReplacePatternF(`(`, "x")))
+	eg(4, err.(*PipeError).Index)
+	eg(5, err.(*PipeError).Name)
+	_, err = PipeE("", LiftAll(Clean, strings.TrimSpace)...)
+	eg(6, err)
+	_, err = PipeE("", nonEmpty)
+	eg(7, err)
+	// Output:
+	// 1: hello
+	// 2: <nil>
+	// 3: pipe stage 1 (required): empty input
+	// 4: 1
+	// 5: ReplacePatternF
+	// 6: <nil>
+	// 7: pipe stage 0 (ExamplePipeE): empty input
+}
+
+func ExamplePipeStages() {
+	positive := func(s string) (string, error) {
+		if n, err := strconv.Atoi(s); err != nil || n <= 0 {
+			return "", errors.New("not a positive number")
+		}
+		return s, nil
+	}
+	s, err := PipeStages(" 42 ", Stage{Name: "trim", Fn: Lift(strings.TrimSpace)}, Stage{Name: "positive", Fn: positive})
+	eg(1, s)
+	eg(2, err)
+	_, err = PipeStages("-1", Stage{Name: "positive", Fn: positive}, Stage{Fn: Lift(Slugify)})
+	eg(3, err)
+	// Output:
+	// 1: 42
+	// 2: <nil>
+	// 3: pipe stage 0 (positive): not a positive number
+}
+
 func ExampleReplaceF() {
 	eg(1, Pipe("abcdefab", ReplaceF("ab", "x", -1)))
 	eg(2, Pipe("abcdefab", ReplaceF("ab", "x", 1)))
@@ -464,12 +1247,38 @@ func ExampleReplacePattern() {
 	// 1: xxbbcc
 }
 
+func ExampleReplacePatternE() {
+	s, err := ReplacePatternE("aabbcc", `b+`, "-")
+	eg(1, s)
+	eg(2, err)
+	_, err = ReplacePatternE("aabbcc", `b+(`, "-")
+	eg(3, err)
+	// Output:
+	// 1: aa-cc
+	// 2: <nil>
+	// 3: error parsing regexp: missing closing ): `b+(`
+}
+
 func ExampleReplacePatternF() {
 	eg(1, Pipe("aabbcc", ReplacePatternF(`a`, "x")))
 	// Output:
 	// 1: xxbbcc
 }
 
+func ExampleReplacePatternFunc() {
+	double := func(groups map[string]string) string {
+		n, _ := strconv.Atoi(groups["n"])
+		return strconv.Itoa(n*2) + groups["unit"]
+	}
+	eg(1, ReplacePatternFunc("a=3px b=10px", `(?P<n>\d+)(?P<unit>px)`, double))
+	eg(2, Pipe("x1 y2", ReplacePatternFuncF(`\d`, func(g map[string]string) string {
+		return "<" + g["0"] + ">"
+	})))
+	// Output:
+	// 1: a=6px b=20px
+	// 2: x<1> y<2>
+}
+
 func ExampleReverse() {
 	eg(1, Reverse("abc"))
 	eg(2, Reverse("中文"))
@@ -478,6 +1287,16 @@ func ExampleReverse() {
 	// 2: 文中
 }
 
+func ExampleReverseGraphemes() {
+	eg(1, ReverseGraphemes("abc"))
+	eg(2, ReverseGraphemes("e\u0301a") == "ae\u0301")
+	eg(3, ReverseGraphemes("a\U0001F468\u200D\U0001F469\u200D\U0001F467b") == "b\U0001F468\u200D\U0001F469\u200D\U0001F467a")
+	// Output:
+	// 1: cba
+	// 2: true
+	// 3: true
+}
+
 func ExampleRight() {
 	eg(1, Right("abcdef", 0))
 	eg(2, Right("abcdef", 1))
@@ -510,6 +1329,93 @@ func ExampleRightF() {
 	// 1: def
 }
 
+func ExampleRightGraphemes() {
+	eg(1, RightGraphemes("cafe\u0301", 2))
+	eg(2, RightGraphemes("cafe\u0301", -2))
+	eg(3, Pipe("cafe\u0301", RightGraphemesF(1)))
+	// Output:
+	// 1: fé
+	// 2: ca
+	// 3: é
+}
+
+func ExampleRightRunes() {
+	eg(1, RightRunes("naïve", 3))
+	eg(2, RightRunes("naïve", -3))
+	eg(3, Pipe("中文字", RightRunesF(2)))
+	// Output:
+	// 1: ïve
+	// 2: naï
+	// 3: 文字
+}
+
+type exampleLogger []string
+
+func (l *exampleLogger) Printf(format string, v ...interface{}) {
+	*l = append(*l, fmt.Sprintf(format, v...))
+}
+
+func ExampleSetLogger() {
+	logger := &exampleLogger{}
+	SetLogger(logger)
+	defer SetLogger(nil)
+	EscapeHTML("<b>")
+	UnescapeHTML("&lt;b&gt;")
+	eg(1, len(*logger))
+	eg(2, (*logger)[0])
+	eg(3, (*logger)[1])
+	// Output:
+	// 1: 2
+	// 2: Use html.EscapeString instead of EscapeHTML
+	// 3: Use html.UnescapeString instead of UnescapeHTML
+}
+
+func ExampleSetRegexpCacheSize() {
+	SetRegexpCacheSize(2)
+	defer SetRegexpCacheSize(DefaultRegexpCacheSize)
+	Match("a", `a`)
+	Match("b", `b`)
+	ReplacePattern("c", `c`, "C")
+	Match("b", `b`)
+	eg(1, regexpCache.order.Len())
+	_, cached := regexpCache.items[`a`]
+	eg(2, cached)
+
+	SetRegexpCacheSize(0)
+	eg(3, Match("abc", `b`))
+	eg(4, regexpCache.order.Len())
+	// Output:
+	// 1: 2
+	// 2: false
+	// 3: true
+	// 4: 0
+}
+
+func ExampleSetTracer() {
+	SetTracer(func(e TraceEvent) {
+		fmt.Printf("%d %s: %q -> %q %v\n", e.Index, e.Name, e.Input, e.Output, e.Err)
+	})
+	defer SetTracer(nil)
+	Pipe(" Hello World ", strings.TrimSpace, Slugify)
+	normalize := MustParsePipeline(`clean | chompLeft "bc" | between "a" "f"`)
+	normalize("\nabcdef \n")
+	fail := func(s string) (string, error) {
+		return "", errors.New("boom")
+	}
+	PipeE("x", Lift(strings.ToUpper), Named("fail", fail))
+	PipeStages("x", Stage{Name: "upper", Fn: Lift(strings.ToUpper)}, Stage{Fn: Named("fail", fail)})
+	// Output:
+	// 0 TrimSpace: " Hello World " -> "Hello World" <nil>
+	// 1 Slugify: "Hello World" -> "hello-world" <nil>
+	// 0 clean: "\nabcdef \n" -> "abcdef" <nil>
+	// 1 chompLeft "bc": "abcdef" -> "abcdef" <nil>
+	// 2 between "a" "f": "abcdef" -> "bcde" <nil>
+	// 0 Named: "x" -> "X" <nil>
+	// 1 fail: "X" -> "" boom
+	// 0 upper: "x" -> "X" <nil>
+	// 1 fail: "X" -> "" boom
+}
+
 func ExampleSliceContains() {
 	eg(1, SliceContains([]string{"foo", "bar"}, "foo"))
 	eg(2, SliceContains(nil, "foo"))
@@ -536,14 +1442,60 @@ func ExampleSliceIndexOf() {
 	// 5: 1
 }
 
+func ExampleSliceGraphemes() {
+	eg(1, SliceGraphemes("he\u0301llo", 1, 3))
+	eg(2, SliceGraphemes("he\u0301llo", 1, -1))
+	eg(3, Pipe("he\u0301llo", SliceGraphemesF(-2, 100)))
+	// Output:
+	// 1: él
+	// 2: éll
+	// 3: lo
+}
+
+func ExampleSliceRunes() {
+	eg(1, SliceRunes("héllo", 1, 3))
+	eg(2, SliceRunes("héllo", 0, -1))
+	eg(3, SliceRunes("héllo", 3, 1))
+	eg(4, Pipe("héllo", SliceRunesF(-3, -1)))
+	// Output:
+	// 1: él
+	// 2: héll
+	// 3:
+	// 4: ll
+}
+
 func ExampleSlugify() {
 	eg(1, Slugify("foo bar"))
 	eg(2, Slugify("foo/bar bah"))
 	eg(3, Slugify("foo-bar--bah"))
+	eg(4, Slugify("Crème Brûlée, don't stop!"))
+	eg(5, Slugify("Москва и Санкт-Петербург"))
+	eg(6, Slugify("userProfileHTML"))
 	// Output:
 	// 1: foo-bar
-	// 2: foobar-bah
+	// 2: foo-bar-bah
 	// 3: foo-bar-bah
+	// 4: crème-brûlée-dont-stop
+	// 5: москва-и-санкт-петербург
+	// 6: user-profile-html
+}
+
+func ExampleSplitPattern() {
+	eg(1, QuoteItems(SplitPattern("a, b;c", `[,;]\s*`, false)))
+	eg(2, QuoteItems(SplitPattern("a, b;c", `[,;]\s*`, true)))
+	eg(3, QuoteItems(SplitPattern("1+2-3=", `[-+=]`, true)))
+	eg(4, QuoteItems(SplitPattern("abc", ``, false)))
+	eg(5, QuoteItems(SplitPattern("", `,`, true)))
+	eg(6, Pipe("a, b;c", SplitPatternF(`[,;]\s*`, "|")))
+	eg(7, Template("{{list | splitPattern '\\s*,\\s*' '/'}}", map[string]string{"list": "x , y,z"}))
+	// Output:
+	// 1: ["a" "b" "c"]
+	// 2: ["a" ", " "b" ";" "c"]
+	// 3: ["1" "+" "2" "-" "3" "=" ""]
+	// 4: ["a" "b" "c"]
+	// 5: [""]
+	// 6: a|b|c
+	// 7: x/y/z
 }
 
 func ExampleStripPunctuation() {
@@ -564,6 +1516,31 @@ func ExampleStripTags() {
 	// 4: <a><p>just some text</p></a>
 }
 
+func ExampleStripTagsChunks() {
+	strip := func(s string) string {
+		r := io.Reader(strings.NewReader(s))
+		if len(s) < 1<<20 {
+			r = iotest.OneByteReader(r)
+		}
+		b, err := io.ReadAll(NewChunkReader(r, StripTagsChunks()))
+		if err != nil {
+			return err.Error()
+		}
+		return string(b)
+	}
+	fmt.Printf("1: %q\n", strip("if a < b then <b>c</b> else d<e"))
+	fmt.Printf("2: %q\n", strip("x </i"))
+	log := strings.Repeat("if a < b then c\n", 100000)
+	fmt.Println("3:", strip(log) == log)
+	long := "<a " + strings.Repeat("x", MaxTagLen) + ">"
+	fmt.Println("4:", strip(long) == long, StripTags(long) == "")
+	// Output:
+	// 1: "if a < b then c else d<e"
+	// 2: "x </i"
+	// 3: true
+	// 4: true true
+}
+
 func ExampleSubstr() {
 	eg(1, Substr("abcdef", 2, -1))
 	eg(2, Substr("abcdef", 2, 0))
@@ -582,6 +1559,38 @@ func ExampleSubstr() {
 	// 7: a
 }
 
+func ExampleSubstrGraphemes() {
+	eg(1, SubstrGraphemes("a\U0001F1EF\U0001F1F5\U0001F1FA\U0001F1F8b", 1, 2))
+	eg(2, SubstrGraphemes("he\u0301llo", 0, 2))
+	eg(3, Pipe("he\u0301llo", SubstrGraphemesF(1, 1)))
+	// Output:
+	// 1: 🇯🇵🇺🇸
+	// 2: hé
+	// 3: é
+}
+
+func ExampleSubstrRunes() {
+	eg(1, SubstrRunes("héllo", 1, 2))
+	eg(2, SubstrRunes("héllo", 2, 100))
+	eg(3, SubstrRunes("héllo", 5, 1))
+	eg(4, Pipe("中文字", SubstrRunesF(1, 1)))
+	// Output:
+	// 1: él
+	// 2: llo
+	// 3:
+	// 4: 文
+}
+
+func ExampleTee() {
+	seen := []string{}
+	f := Compose(Clean, Tee(func(s string) { seen = append(seen, s) }), Slugify)
+	eg(1, f("  Hello World "))
+	eg(2, QuoteItems(seen))
+	// Output:
+	// 1: hello-world
+	// 2: ["Hello World"]
+}
+
 func ExampleTemplateWithDelimiters() {
 	eg(1, TemplateWithDelimiters("Hello {{name}} at {{date-year}}", map[string]interface{}{"name": "foo", "date-year": 2014}, "{{", "}}"))
 	eg(2, TemplateWithDelimiters("Hello #{name} at #{date-year}", map[string]interface{}{"name": "foo", "date-year": 2014}, "#{", "}"))
@@ -589,6 +1598,7 @@ func ExampleTemplateWithDelimiters() {
 	eg(4, TemplateWithDelimiters("Hello [name] at [date-year]", map[string]interface{}{"name": "foo", "date-year": 2014}, "[", "]"))
 	eg(5, TemplateWithDelimiters("Hello *name* at *date-year*", map[string]interface{}{"name": "foo", "date-year": 2014}, "*", "*"))
 	eg(6, TemplateWithDelimiters("Hello $name$ at $date-year$", map[string]interface{}{"name": "foo", "date-year": 2014}, "$", "$"))
+	eg(7, TemplateWithDelimiters("{{a}} {{b}} {{c", map[string]interface{}{"a": "{{b}}"}, "{{", "}}"))
 	// Output:
 	// 1: Hello foo at 2014
 	// 2: Hello foo at 2014
@@ -596,6 +1606,7 @@ func ExampleTemplateWithDelimiters() {
 	// 4: Hello foo at 2014
 	// 5: Hello foo at 2014
 	// 6: Hello foo at 2014
+	// 7: {{b}} {{b}} {{c
 }
 
 func ExampleTemplate() {
@@ -603,10 +1614,152 @@ func ExampleTemplate() {
 	eg(2, Template("Hello {{name}}", map[string]interface{}{"name": ""}))
 	SetTemplateDelimiters("{", "}")
 	eg(3, Template("Hello {name} at {date-year}", map[string]interface{}{"name": "foo", "date-year": 2014}))
+	eg(4, Template("Hello {user.name} from {user.address.city}", map[string]interface{}{
+		"user": map[string]interface{}{"name": "foo", "address": map[string]string{"city": "Oslo"}},
+	}))
+	SetTemplateDelimiters("{{", "}}")
 	// Output:
 	// 1: Hello foo at 2014
 	// 2: Hello
 	// 3: Hello foo at 2014
+	// 4: Hello foo from Oslo
+}
+
+type exampleLookuper map[string]int
+
+func (l exampleLookuper) Lookup(key string) (interface{}, bool) {
+	v, ok := l[strings.ToLower(key)]
+	return v * 10, ok
+}
+
+func ExampleTemplateLookup() {
+	type address struct {
+		City string `json:"city"`
+		Zip  string `json:"-"`
+	}
+	type user struct {
+		Name    string   `json:"name,omitempty"`
+		Address *address `json:"address"`
+		Tags    []string
+		secret  string
+	}
+	u := user{Name: "foo", Address: &address{City: "Oslo", Zip: "0150"}, Tags: []string{"a", "b"}, secret: "x"}
+	data := map[string]interface{}{
+		"user":     u,
+		"items":    []map[string]string{{"name": "first"}, {"name": "second"}},
+		"dot.key":  "verbatim",
+		"nothing":  nil,
+		"counters": exampleLookuper{"hits": 4},
+	}
+	lookup := func(key string) string {
+		v, ok := TemplateLookup(data, key)
+		return fmt.Sprintf("%v %v", v, ok)
+	}
+	eg(1, lookup("user.name"))
+	eg(2, lookup("user.address.city"))
+	eg(3, lookup("user.Address.city"))
+	eg(4, lookup("user.address.Zip"))
+	eg(5, lookup("user.Tags.1"))
+	eg(6, lookup("user.Tags.2"))
+	eg(7, lookup("user.secret"))
+	eg(8, lookup("items.1.name"))
+	eg(9, lookup("dot.key"))
+	eg(10, lookup("nothing"))
+	eg(11, lookup("counters.HITS"))
+	eg(12, TemplateWithDelimiters("<%name%> lives in <%address.city%>", u, "<%", "%>"))
+	type Meta struct {
+		ID int
+	}
+	type post struct {
+		*Meta
+		Title string
+	}
+	eg(13, Template("{{Title}} #{{ID}}", post{Meta: &Meta{ID: 7}, Title: "a"}))
+	eg(14, Template("{{Title}} #{{ID}}", post{Title: "b"}))
+	// Output:
+	// 1: foo true
+	// 2: Oslo true
+	// 3: Oslo true
+	// 4: <nil> false
+	// 5: b true
+	// 6: <nil> false
+	// 7: <nil> false
+	// 8: second true
+	// 9: verbatim true
+	// 10: <nil> false
+	// 11: 40 true
+	// 12: foo lives in Oslo
+	// 13: a #7
+	// 14: b #{{ID}}
+}
+
+func ExampleTemplater() {
+	erb := NewTemplater(TemplateOptions{Opening: "<%", Closing: "%>", Escape: "\\"})
+	erb.RegisterFilter("stars", func(s string) string {
+		return "*" + s + "*"
+	})
+	html := NewTemplater(TemplateOptions{Escaper: EscapeHTML})
+	data := map[string]interface{}{"name": "<b>foo</b>"}
+
+	eg(1, erb.Template("Hello <%name | stars%> \\<%name%>", data))
+	eg(2, html.Template("Hello {{name}} <%name%>", data))
+	eg(3, Template("Hello {{name | stars}}", data))
+	opening, closing := erb.Delimiters()
+	eg(4, opening+" "+closing)
+	opening, closing = TemplateDelimiters()
+	eg(5, opening+" "+closing)
+
+	t, err := erb.Compile("<%name | upper%>")
+	s, _ := t.Render(data)
+	eg(6, s)
+	eg(7, err)
+	_, err = html.Compile("{{name | stars}}")
+	eg(8, err)
+	// Output:
+	// 1: Hello *<b>foo</b>* <%name%>
+	// 2: Hello &lt;b&gt;foo&lt;/b&gt; <%name%>
+	// 3: Hello {{name | stars}}
+	// 4: <% %>
+	// 5: {{ }}
+	// 6: <B>FOO</B>
+	// 7: <nil>
+	// 8: template:1:10: unknown filter "stars"
+}
+
+func ExampleTitleLocale() {
+	eg(1, TitleLocale("the quick-brown fox's tale", ""))
+	eg(2, TitleLocale("iyi İŞLER ıssız", "tr"))
+	eg(3, TitleLocale("ΟΔΟΣ ΑΘΗΝΩΝ", "el"))
+	eg(4, Pipe("diyarbakır iLİ", TitleLocaleF("az")))
+	// Output:
+	// 1: The Quick-Brown Fox's Tale
+	// 2: İyi İşler Issız
+	// 3: Οδος Αθηνων
+	// 4: Diyarbakır İli
+}
+
+func ExampleTitleize() {
+	eg(1, Titleize("the lord of the rings", TitleSimple))
+	eg(2, Titleize("the lord of the rings", TitleAP))
+	eg(3, Titleize("gone with the wind", TitleAP))
+	eg(4, Titleize("gone with the wind", TitleChicago))
+	eg(5, Titleize("what are you looking at", TitleAP)+" / "+Titleize("what are you looking at", TitleAPA))
+	eg(6, Titleize("state-of-the-art design for the iPhone", TitleChicago))
+	eg(7, Titleize("NASA and the ESA: a history of space", TitleAP))
+	eg(8, Titleize("THE RETURN OF THE KING", TitleChicago))
+	eg(9, Pipe("the road to el dorado — a journey", TitleizeF(TitleAPA)))
+	eg(10, Template("{{name | titleize chicago}}", map[string]string{"name": "a tale of two cities"}))
+	// Output:
+	// 1: The Lord Of The Rings
+	// 2: The Lord of the Rings
+	// 3: Gone With the Wind
+	// 4: Gone with the Wind
+	// 5: What Are You Looking At / What Are You Looking at
+	// 6: State-of-the-Art Design for the iPhone
+	// 7: NASA and the ESA: A History of Space
+	// 8: The Return of the King
+	// 9: The Road to El Dorado — A Journey
+	// 10: A Tale of Two Cities
 }
 
 func ExampleToArgv() {
@@ -667,16 +1820,166 @@ func ExampleToIntOr() {
 	// 4: -1
 }
 
+func ExampleToLowerLocale() {
+	eg(1, ToLowerLocale("DİYARBAKIR", "tr"))
+	eg(2, ToLowerLocale("DİYARBAKIR", "en"))
+	eg(3, ToLowerLocale("I\u0307", "az"))
+	eg(4, ToLowerLocale("ΟΔΟΣ ΣΟΦΟΣ.", ""))
+	fmt.Printf("5: %+q\n", ToLowerLocale("ÌĮ\u0301 J", "lt"))
+	// Output:
+	// 1: diyarbakır
+	// 2: diyarbakir
+	// 3: i
+	// 4: οδος σοφος.
+	// 5: "i\u0307\u0300\u012f\u0307\u0301 j"
+}
+
+func ExampleToUpperLocale() {
+	eg(1, ToUpperLocale("istanbul ılık", "tr"))
+	eg(2, ToUpperLocale("istanbul ılık", "en-US"))
+	eg(3, ToUpperLocale("straße", "de"))
+	fmt.Printf("4: %+q\n", ToUpperLocale("i\u0307\u0301 j\u0307", "lt"))
+	eg(5, Pipe("çiğdem", ToUpperLocaleF("az")))
+	// Output:
+	// 1: İSTANBUL ILIK
+	// 2: ISTANBUL ILIK
+	// 3: STRASSE
+	// 4: "I\u0301 J"
+	// 5: ÇİĞDEM
+}
+
+func ExampleTruncate() {
+	eg(1, Truncate("Hello, world", 5, ""))
+	eg(2, Truncate("Hello, world", 10, ""))
+	eg(3, Truncate("Hello, world", 12, ""))
+	eg(4, Truncate("Hello world", 8, "…"))
+	eg(5, Truncate("Supercalifragilistic", 10, "..."))
+	eg(6, Truncate("héllo wörld", 9, "…"))
+	eg(7, Truncate("Hello world", 2, "..."))
+	eg(8, Pipe("Hello world", TruncateF(7, "~")))
+	// Output:
+	// 1: He...
+	// 2: Hello,...
+	// 3: Hello, world
+	// 4: Hello…
+	// 5: Superca...
+	// 6: héllo…
+	// 7: ..
+	// 8: Hello~
+}
+
+func ExampleTruncateMiddle() {
+	eg(1, TruncateMiddle("/usr/local/lib/go/src/file.go", 14, "…"))
+	eg(2, TruncateMiddle("the quick brown fox jumps", 20, "..."))
+	eg(3, Pipe("abcdefghij", TruncateMiddleF(7, "...")))
+	// Output:
+	// 1: /usr/…/file.go
+	// 2: the...fox jumps
+	// 3: ab...ij
+}
+
+func ExampleTruncateStart() {
+	eg(1, TruncateStart("the quick brown fox", 12, "..."))
+	eg(2, Pipe("/var/log/app/error.log", TruncateStartF(14, "…")))
+	// Output:
+	// 1: ...brown fox
+	// 2: …app/error.log
+}
+
+func ExampleTruncateWith() {
+	opts := TruncateOptions{Omission: "…", Width: true}
+	eg(1, TruncateWith("中文字幕很长", 7, opts))
+	eg(2, TruncateWith("hello 中文", 8, opts))
+	opts.Position = TruncateAtMiddle
+	eg(3, Pipe("abcdefgh", TruncateWithF(5, opts)))
+	opts = TruncateOptions{Omission: "…", Width: true}
+	eg(4, TruncateWith("αβγδε", 5, opts))
+	opts.AmbiguousIsWide = true
+	eg(5, TruncateWith("αβγδε", 5, opts))
+	// Output:
+	// 1: 中文字…
+	// 2: hello…
+	// 3: ab…gh
+	// 4: αβγδε
+	// 5: α…
+}
+
 func ExampleUnderscore() {
 	eg(1, Underscore("fooBar"))
 	eg(2, Underscore("FooBar"))
 	eg(3, Underscore(""))
 	eg(4, Underscore("x"))
+	eg(5, Underscore("XMLHttpRequest"))
+	eg(6, Underscore("base64Encode-data"))
+	eg(7, Underscore("HTTPServer"))
 	// Output:
 	// 1: foo_bar
 	// 2: _foo_bar
 	// 3:
 	// 4: x
+	// 5: _xml_http_request
+	// 6: base64_encode_data
+	// 7: _http_server
+}
+
+func ExampleWidth() {
+	eg(1, Width("hello"))
+	eg(2, Width("中文"))
+	eg(3, Width("e\u0301"))
+	eg(4, Width("\x1b[31mred\x1b[0m"))
+	eg(5, Width("\U0001F468\u200D\U0001F469\u200D\U0001F467"))
+	eg(6, Width("\U0001F1EF\U0001F1F5"))
+	eg(7, Width("αβγ"))
+	eg(8, WidthWith("αβγ", WidthOptions{AmbiguousIsWide: true}))
+	// Output:
+	// 1: 5
+	// 2: 4
+	// 3: 1
+	// 4: 3
+	// 5: 2
+	// 6: 2
+	// 7: 3
+	// 8: 6
+}
+
+func ExampleWhen() {
+	shout := When(IsLower, strings.ToUpper)
+	eg(1, shout("abc"))
+	eg(2, shout("Abc"))
+	quiet := Unless(IsEmpty, EnsureSuffixF("."))
+	eg(3, quiet("done"))
+	eg(4, QuoteItems([]string{quiet(" ")}))
+	// Output:
+	// 1: ABC
+	// 2: Abc
+	// 3: done.
+	// 4: [" "]
+}
+
+func ExampleWords() {
+	eg(1, QuoteItems(Words("fooBar_baz-qux quux")))
+	eg(2, QuoteItems(Words("XMLHttpRequest")))
+	eg(3, QuoteItems(Words("HTTP2Server base64Encode 2nd")))
+	eg(4, QuoteItems(Words("  __don't-stop__  ")))
+	eg(5, QuoteItems(Words("ÉcoleNormale")))
+	eg(6, len(Words(" -_ ")))
+	// Output:
+	// 1: ["foo" "Bar" "baz" "qux" "quux"]
+	// 2: ["XML" "Http" "Request"]
+	// 3: ["HTTP2" "Server" "base64" "Encode" "2nd"]
+	// 4: ["don't" "stop"]
+	// 5: ["École" "Normale"]
+	// 6: 0
+}
+
+func ExampleWordsLocale() {
+	eg(1, QuoteItems(WordsLocale("IJsselMeer ijsselmeer", "nl")))
+	eg(2, QuoteItems(WordsLocale("IJsselMeer", "en")))
+	eg(3, QuoteItems(WordsLocale("HIJKLMeer", "nl")))
+	// Output:
+	// 1: ["IJssel" "Meer" "ijsselmeer"]
+	// 2: ["I" "Jssel" "Meer"]
+	// 3: ["HIJKL" "Meer"]
 }
 
 func ExampleWrapHTML() {
@@ -0,0 +1,278 @@
+package str
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultMaxRecord is the default limit on the bytes a streaming function
+// holds in memory at once.
+const DefaultMaxRecord = 1 << 20
+
+// ErrRecordTooLong is returned by streaming functions when a record, or the
+// input a ChunkFilter must see before it can be filtered, exceeds the limit.
+var ErrRecordTooLong = errors.New("str: record too long")
+
+// RecordOptions configures FilterRecords.
+type RecordOptions struct {
+	// Separator ends each record. The default, "\n", also treats "\r\n" as
+	// a line ending.
+	Separator string
+	// MaxRecord limits the length of a record in bytes. The default is
+	// DefaultMaxRecord.
+	MaxRecord int
+}
+
+// FilterLines applies f to each line read from r and writes the results to
+// w. Unlike Lines, FilterLines preserves the original line endings: f sees
+// a line without its "\n" or "\r\n", which is written back after the
+// result. Lines longer than DefaultMaxRecord fail with ErrRecordTooLong.
+func FilterLines(w io.Writer, r io.Reader, f func(string) string) error {
+	return FilterRecords(w, r, f, RecordOptions{})
+}
+
+// FilterRecords applies f to each record read from r and writes the
+// results, each followed by the separator that ended it, to w. A final
+// record without a separator is filtered and written without one. Only one
+// record is held in memory at a time. On error, the records before the
+// failing one have been written.
+func FilterRecords(w io.Writer, r io.Reader, f func(string) string, opts RecordOptions) error {
+	bw := bufio.NewWriter(w)
+	err := filterRecords(bw, r, f, opts)
+	if ferr := bw.Flush(); err == nil {
+		err = ferr
+	}
+	return err
+}
+
+func filterRecords(bw *bufio.Writer, r io.Reader, f func(string) string, opts RecordOptions) error {
+	sep := opts.Separator
+	if sep == "" {
+		sep = "\n"
+	}
+	max := opts.MaxRecord
+	if max <= 0 {
+		max = DefaultMaxRecord
+	}
+
+	br := bufio.NewReader(r)
+	var record []byte
+	for {
+		chunk, err := br.ReadSlice(sep[len(sep)-1])
+		record = append(record, chunk...)
+		if len(record) > max+len(sep) {
+			return ErrRecordTooLong
+		}
+		if err == bufio.ErrBufferFull || err == nil && !bytes.HasSuffix(record, []byte(sep)) {
+			continue
+		}
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		if len(record) > 0 {
+			body, ending := record, []byte(nil)
+			if bytes.HasSuffix(record, []byte(sep)) {
+				body, ending = record[:len(record)-len(sep)], record[len(record)-len(sep):]
+				if sep == "\n" && bytes.HasSuffix(body, []byte("\r")) {
+					body, ending = body[:len(body)-1], record[len(body)-1:]
+				}
+			}
+			if len(body) > max {
+				return ErrRecordTooLong
+			}
+			bw.WriteString(f(string(body)))
+			if _, werr := bw.Write(ending); werr != nil {
+				return werr
+			}
+			record = record[:0]
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// ChunkFilter is a filter that can be applied to a stream a chunk at a
+// time. Split returns the length of the longest prefix of data that Filter
+// can process independently of the bytes that follow, or 0 if it needs more
+// input. The remaining bytes are held until more arrive or the stream ends.
+type ChunkFilter struct {
+	Filter func(string) string
+	Split  func(data []byte) int
+	// Max limits the bytes held waiting for a split. The default is
+	// DefaultMaxRecord.
+	Max int
+}
+
+// CleanChunks returns a ChunkFilter for Clean. It splits between two
+// non-whitespace characters, so runs of whitespace are collapsed and the
+// stream is trimmed just as if Clean were applied to all of it.
+func CleanChunks() ChunkFilter {
+	return ChunkFilter{Filter: Clean, Split: splitClean}
+}
+
+// MaxTagLen is the longest tag StripTagsChunks holds back while waiting
+// for its closing ">". A longer one is released as text.
+const MaxTagLen = 4 << 10
+
+// StripTagsChunks returns a ChunkFilter for StripTags with tags. It never
+// splits inside a tag: a "<", optionally followed by "/", and then a letter,
+// is held until its ">" arrives or it exceeds MaxTagLen. Other "<"s, as in
+// "if a < b", are released at once, so unlike StripTags on the whole
+// stream, a tag-like run that does not start that way is not stripped if
+// it spans a split.
+func StripTagsChunks(tags ...string) ChunkFilter {
+	return ChunkFilter{
+		Filter: func(s string) string {
+			return StripTags(s, tags...)
+		},
+		Split: splitStripTags,
+	}
+}
+
+func splitClean(data []byte) int {
+	n := 0
+	prevSpace := true
+	for i := 0; i < len(data) && utf8.FullRune(data[i:]); {
+		r, size := utf8.DecodeRune(data[i:])
+		space := r == ' ' || r == '\t' || r == '\n' || r == '\f' || r == '\r' || r == '\u00a0'
+		if !space && !prevSpace {
+			n = i
+		}
+		prevSpace = space
+		i += size
+	}
+	return n
+}
+
+func splitStripTags(data []byte) int {
+	i := bytes.LastIndexByte(data, '<')
+	if i < 0 || bytes.IndexByte(data[i:], '>') >= 0 || len(data)-i > MaxTagLen || !isTagStart(data[i+1:]) {
+		return len(data)
+	}
+	return i
+}
+
+// isTagStart reports whether data, which follows a "<", may still turn out
+// to be a tag name, optionally preceded by "/".
+func isTagStart(data []byte) bool {
+	data = bytes.TrimPrefix(data, []byte("/"))
+	if !utf8.FullRune(data) {
+		return true
+	}
+	r, _ := utf8.DecodeRune(data)
+	return unicode.IsLetter(r)
+}
+
+// chunker holds the input a ChunkFilter is waiting on.
+type chunker struct {
+	cf      ChunkFilter
+	pending []byte
+}
+
+// feed adds p to the pending input and returns the filtered output of
+// whatever can be split off.
+func (c *chunker) feed(p []byte) ([]byte, error) {
+	c.pending = append(c.pending, p...)
+	n := c.cf.Split(c.pending)
+	var out []byte
+	if n > 0 {
+		out = []byte(c.cf.Filter(string(c.pending[:n])))
+		c.pending = append(c.pending[:0], c.pending[n:]...)
+	}
+	max := c.cf.Max
+	if max <= 0 {
+		max = DefaultMaxRecord
+	}
+	if len(c.pending) > max {
+		return out, ErrRecordTooLong
+	}
+	return out, nil
+}
+
+// flush returns the filtered output of the pending input at the end of the
+// stream.
+func (c *chunker) flush() []byte {
+	if len(c.pending) == 0 {
+		return nil
+	}
+	out := []byte(c.cf.Filter(string(c.pending)))
+	c.pending = nil
+	return out
+}
+
+var errChunkWriterClosed = errors.New("str: write to closed chunk writer")
+
+type chunkWriter struct {
+	chunker
+	w   io.Writer
+	err error
+}
+
+// NewChunkWriter returns a writer that applies cf to the bytes written to
+// it and writes the result to w. Close must be called to write the output
+// held for the end of the stream; it does not close w.
+func NewChunkWriter(w io.Writer, cf ChunkFilter) io.WriteCloser {
+	return &chunkWriter{chunker: chunker{cf: cf}, w: w}
+}
+
+func (cw *chunkWriter) Write(p []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	out, err := cw.feed(p)
+	if _, werr := cw.w.Write(out); werr != nil {
+		err = werr
+	}
+	cw.err = err
+	return len(p), err
+}
+
+func (cw *chunkWriter) Close() error {
+	if cw.err != nil {
+		return cw.err
+	}
+	if _, err := cw.w.Write(cw.flush()); err != nil {
+		cw.err = err
+		return err
+	}
+	cw.err = errChunkWriterClosed
+	return nil
+}
+
+type chunkReader struct {
+	chunker
+	r   io.Reader
+	buf []byte
+	out []byte
+	err error
+}
+
+// NewChunkReader returns a reader that applies cf to the bytes read from r.
+func NewChunkReader(r io.Reader, cf ChunkFilter) io.Reader {
+	return &chunkReader{chunker: chunker{cf: cf}, r: r, buf: make([]byte, 32<<10)}
+}
+
+func (cr *chunkReader) Read(p []byte) (int, error) {
+	for len(cr.out) == 0 {
+		if cr.err != nil {
+			return 0, cr.err
+		}
+		n, err := cr.r.Read(cr.buf)
+		cr.out, cr.err = cr.feed(cr.buf[:n])
+		if err == io.EOF {
+			cr.out = append(cr.out, cr.flush()...)
+		}
+		if cr.err == nil {
+			cr.err = err
+		}
+	}
+	n := copy(p, cr.out)
+	cr.out = cr.out[n:]
+	return n, nil
+}
@@ -0,0 +1,407 @@
+package str
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Lookuper is implemented by template data that resolves keys itself.
+type Lookuper interface {
+	Lookup(key string) (value interface{}, ok bool)
+}
+
+// MissingKeyPolicy controls how a CompiledTemplate renders a placeholder
+// whose key has no value.
+type MissingKeyPolicy int
+
+const (
+	// MissingKeyLeave leaves the placeholder in the output untouched.
+	MissingKeyLeave MissingKeyPolicy = iota
+	// MissingKeyEmpty replaces the placeholder with an empty string.
+	MissingKeyEmpty
+	// MissingKeyError makes Render return a *TemplateError.
+	MissingKeyError
+)
+
+// TemplateOptions configures CompileTemplate and NewTemplater.
+type TemplateOptions struct {
+	// Opening and Closing delimit placeholders. Empty values default to the
+	// delimiters set with SetTemplateDelimiters, or "{{" and "}}" for
+	// NewTemplater.
+	Opening, Closing string
+	// Missing is the policy for placeholders without a value.
+	Missing MissingKeyPolicy
+	// Escape, when placed right before Opening, makes the opening delimiter
+	// literal text, for example \{{ with Escape set to "\". The escape
+	// itself is removed from the output.
+	Escape string
+	// Escaper, if set, escapes every rendered value after its filters, for
+	// example html.EscapeString.
+	Escaper func(string) string
+}
+
+// Templater compiles and renders templates with its own delimiters, escape
+// rules and filters, so independent users of the package do not clobber
+// each other's settings. A Templater is safe for concurrent use. The
+// package-level Template, SetTemplateDelimiters and RegisterFilter functions
+// use a default Templater.
+type Templater struct {
+	mu      sync.RWMutex
+	opts    TemplateOptions
+	filters *filterRegistry
+}
+
+var defaultTemplater = &Templater{
+	opts:    TemplateOptions{Opening: "{{", Closing: "}}"},
+	filters: defaultFilters,
+}
+
+// NewTemplater returns a Templater configured by opts. It starts with a copy
+// of the filters registered with RegisterFilter and RegisterFilterFactory.
+func NewTemplater(opts TemplateOptions) *Templater {
+	if opts.Opening == "" {
+		opts.Opening = "{{"
+	}
+	if opts.Closing == "" {
+		opts.Closing = "}}"
+	}
+	return &Templater{opts: opts, filters: defaultFilters.clone()}
+}
+
+// Compile parses template s with the Templater's settings.
+func (t *Templater) Compile(s string) (*CompiledTemplate, error) {
+	return compileTemplate(s, t.options(), t.filters, false)
+}
+
+// Delimiters returns the opening and closing placeholder delimiters.
+func (t *Templater) Delimiters() (opening, closing string) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.opts.Opening, t.opts.Closing
+}
+
+// RegisterFilter makes fn available by name to this Templater only.
+func (t *Templater) RegisterFilter(name string, fn func(string) string) {
+	t.filters.register(name, filter0(fn))
+}
+
+// RegisterFilterFactory makes a filter that takes arguments available by
+// name to this Templater only. See RegisterFilterFactory.
+func (t *Templater) RegisterFilterFactory(name string, factory func(args []string) (func(string) string, error)) {
+	t.filters.register(name, factory)
+}
+
+// SetDelimiters sets the opening and closing placeholder delimiters.
+func (t *Templater) SetDelimiters(opening, closing string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.opts.Opening = opening
+	t.opts.Closing = closing
+}
+
+// Template renders s with values from data like the package-level Template.
+// Placeholders without a value and malformed placeholders are left as is.
+func (t *Templater) Template(s string, data interface{}) string {
+	opts := t.options()
+	opts.Missing = MissingKeyLeave
+	ct, _ := compileTemplate(s, opts, t.filters, true)
+	result, _ := ct.Render(data)
+	return result
+}
+
+func (t *Templater) options() TemplateOptions {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.opts
+}
+
+// TemplateError is returned for malformed placeholders and, depending on
+// the MissingKeyPolicy, for placeholders without a value.
+type TemplateError struct {
+	// Offset is the byte offset of the placeholder in the template source.
+	Offset int
+	// Line and Column are the 1-based position of Offset.
+	Line, Column int
+	Msg          string
+}
+
+func (e *TemplateError) Error() string {
+	return fmt.Sprintf("template:%d:%d: %s", e.Line, e.Column, e.Msg)
+}
+
+// CompiledTemplate is a template parsed once by CompileTemplate that can be
+// rendered many times. A CompiledTemplate is safe for concurrent use.
+type CompiledTemplate struct {
+	src     string
+	nodes   []templateNode
+	missing MissingKeyPolicy
+	escaper func(string) string
+}
+
+// templateNode is either literal text or a placeholder. For placeholders
+// text holds the raw placeholder including delimiters.
+type templateNode struct {
+	text    string
+	key     string
+	filters []func(string) string
+	offset  int
+}
+
+// CompileTemplate parses template s. Unclosed and empty placeholders and
+// unknown filters are reported as a *TemplateError.
+//
+// A placeholder may pipe its value through filters registered with
+// RegisterFilter, for example {{name | trim | slugify}} or
+// {{title | truncate 20 "…"}}. Arguments are separated by spaces and may be
+// double quoted with Go escapes or single quoted as is.
+func CompileTemplate(s string, opts TemplateOptions) (*CompiledTemplate, error) {
+	opening, closing := defaultTemplater.Delimiters()
+	if opts.Opening == "" {
+		opts.Opening = opening
+	}
+	if opts.Closing == "" {
+		opts.Closing = closing
+	}
+	return compileTemplate(s, opts, defaultFilters, false)
+}
+
+// MustCompileTemplate is like CompileTemplate but panics if s cannot be
+// parsed.
+func MustCompileTemplate(s string, opts TemplateOptions) *CompiledTemplate {
+	t, err := CompileTemplate(s, opts)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Render replaces each placeholder with its value from data. See
+// TemplateLookup for how keys are resolved.
+func (t *CompiledTemplate) Render(data interface{}) (string, error) {
+	var buf bytes.Buffer
+	for _, node := range t.nodes {
+		if node.key == "" {
+			buf.WriteString(node.text)
+			continue
+		}
+		if v, ok := TemplateLookup(data, node.key); ok {
+			value := Pipe(fmt.Sprintf("%v", v), node.filters...)
+			if t.escaper != nil {
+				value = t.escaper(value)
+			}
+			buf.WriteString(value)
+			continue
+		}
+		switch t.missing {
+		case MissingKeyLeave:
+			buf.WriteString(node.text)
+		case MissingKeyError:
+			return "", newTemplateError(t.src, node.offset, "no value for key %q", node.key)
+		}
+	}
+	return buf.String(), nil
+}
+
+// TemplateLookup resolves key against data. Keys are dot separated paths
+// such as "user.address.city" or "items.0.name" where each segment indexes
+// a Lookuper, a map with string keys, a struct field or a slice element.
+// Struct fields are matched by their json tag name, then by field name. A key
+// present verbatim in a map, such as "date.year", takes precedence over
+// the path. Nil values are reported as not found.
+func TemplateLookup(data interface{}, key string) (interface{}, bool) {
+	if v, ok := lookupSegment(data, key); ok || !strings.Contains(key, ".") {
+		return v, ok
+	}
+	v := data
+	for _, segment := range strings.Split(key, ".") {
+		var ok bool
+		if v, ok = lookupSegment(v, segment); !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// lookupSegment resolves a single path segment against data.
+func lookupSegment(data interface{}, segment string) (interface{}, bool) {
+	switch d := data.(type) {
+	case nil:
+		return nil, false
+	case Lookuper:
+		v, ok := d.Lookup(segment)
+		return v, ok && v != nil
+	case map[string]interface{}:
+		v := d[segment]
+		return v, v != nil
+	}
+
+	rv := reflect.ValueOf(data)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, false
+		}
+		rv = rv.Elem()
+	}
+
+	var v reflect.Value
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		v = rv.MapIndex(reflect.ValueOf(segment).Convert(rv.Type().Key()))
+	case reflect.Struct:
+		v = structField(rv, segment)
+	case reflect.Slice, reflect.Array:
+		i, err := strconv.Atoi(segment)
+		if err != nil || i < 0 || i >= rv.Len() {
+			return nil, false
+		}
+		v = rv.Index(i)
+	}
+	if !v.IsValid() || !v.CanInterface() {
+		return nil, false
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return nil, false
+		}
+	}
+	return v.Interface(), true
+}
+
+// structField finds the exported field of rv named name by its json tag or,
+// failing that, its Go name.
+func structField(rv reflect.Value, name string) reflect.Value {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if tag == name && tag != "-" {
+			return rv.Field(i)
+		}
+	}
+	if field, ok := t.FieldByName(name); ok && field.PkgPath == "" {
+		if tag := field.Tag.Get("json"); tag != "-" {
+			// A promoted field behind a nil embedded pointer is missing.
+			v, err := rv.FieldByIndexErr(field.Index)
+			if err == nil {
+				return v
+			}
+		}
+	}
+	return reflect.Value{}
+}
+
+// compileTemplate parses s into a CompiledTemplate. When lenient is set
+// malformed placeholders are kept as literal text, and placeholders with
+// invalid filters are looked up as a plain key, instead of returning an
+// error.
+func compileTemplate(s string, opts TemplateOptions, registry *filterRegistry, lenient bool) (*CompiledTemplate, error) {
+	nodes, err := parseTemplate(s, opts, registry, lenient)
+	if err != nil {
+		return nil, err
+	}
+	return &CompiledTemplate{src: s, nodes: nodes, missing: opts.Missing, escaper: opts.Escaper}, nil
+}
+
+// parseTemplate splits s into text and placeholder nodes resolving filters
+// with registry. See compileTemplate for lenient.
+func parseTemplate(s string, opts TemplateOptions, registry *filterRegistry, lenient bool) ([]templateNode, error) {
+	opening, closing, escape := opts.Opening, opts.Closing, opts.Escape
+	if opening == "" || closing == "" {
+		if lenient {
+			return []templateNode{{text: s}}, nil
+		}
+		return nil, &TemplateError{Line: 1, Column: 1, Msg: "empty delimiter"}
+	}
+	nodes := []templateNode{}
+	text := 0
+	for i := 0; i < len(s); {
+		start := IndexOf(s, opening, i)
+		if start < 0 {
+			break
+		}
+		inner := start + len(opening)
+		if escape != "" && strings.HasSuffix(s[text:start], escape) {
+			// drop the escape and keep the delimiter as text
+			nodes = append(nodes, templateNode{text: s[text : start-len(escape)], offset: text})
+			text, i = start, inner
+			continue
+		}
+		end := IndexOf(s, closing, inner)
+		if end < 0 {
+			if lenient {
+				break
+			}
+			return nil, newTemplateError(s, start, "unclosed placeholder, expected %q", closing)
+		}
+		key := strings.TrimSpace(s[inner:end])
+		if key == "" {
+			if lenient {
+				i = inner
+				continue
+			}
+			return nil, newTemplateError(s, start, "empty placeholder")
+		}
+		var filters []func(string) string
+		if strings.Contains(key, "|") {
+			var err error
+			key, filters, err = parsePlaceholder(s[inner:end], registry)
+			if serr, ok := err.(*syntaxError); ok {
+				if !lenient {
+					return nil, newTemplateError(s, inner+serr.offset, "%s", serr.msg)
+				}
+				key, filters = strings.TrimSpace(s[inner:end]), nil
+			}
+		}
+		if start > text {
+			nodes = append(nodes, templateNode{text: s[text:start], offset: text})
+		}
+		i = end + len(closing)
+		nodes = append(nodes, templateNode{text: s[start:i], key: key, filters: filters, offset: start})
+		text = i
+	}
+	if text < len(s) {
+		nodes = append(nodes, templateNode{text: s[text:], offset: text})
+	}
+	return nodes, nil
+}
+
+// parsePlaceholder parses a placeholder of the form key | filter args... .
+func parsePlaceholder(s string, registry *filterRegistry) (string, []func(string) string, error) {
+	tokens, err := tokenizePipeline(s)
+	if err != nil {
+		return "", nil, err
+	}
+	if tokens[0].pipe {
+		return "", nil, &syntaxError{tokens[0].offset, "missing key before filters"}
+	}
+	if len(tokens) == 1 {
+		return tokens[0].text, nil, nil
+	}
+	if !tokens[1].pipe {
+		return "", nil, &syntaxError{tokens[1].offset, "expected | after key"}
+	}
+	filters, err := registry.compilePipeline(tokens[2:], len(s))
+	return tokens[0].text, filters, err
+}
+
+func newTemplateError(src string, offset int, format string, args ...interface{}) *TemplateError {
+	line := strings.Count(src[:offset], "\n") + 1
+	column := offset - strings.LastIndex(src[:offset], "\n")
+	return &TemplateError{
+		Offset: offset,
+		Line:   line,
+		Column: column,
+		Msg:    fmt.Sprintf(format, args...),
+	}
+}
@@ -0,0 +1,140 @@
+package str
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// titleStyles names the title styles for the titleize filter.
+var titleStyles = map[string]TitleStyle{
+	"simple":  TitleSimple,
+	"ap":      TitleAP,
+	"chicago": TitleChicago,
+	"apa":     TitleAPA,
+}
+
+// shortMinorWords are the articles, and the conjunctions and prepositions
+// of three letters or fewer, that AP and APA lower case.
+var shortMinorWords = wordSet(`a an the and as but for if nor or so yet
+	at by in of off on out per to up via`)
+
+// minorWords holds the words each style lower cases inside a title.
+var minorWords = map[TitleStyle]map[string]bool{
+	TitleAP: shortMinorWords,
+	TitleChicago: wordSet(`a an the and but for nor or as to
+		aboard about above across after against along amid among around at
+		before behind below beneath beside besides between beyond but by
+		concerning despite down during except following from in inside into
+		like near of off on onto opposite out outside over past per plus
+		regarding since than through throughout till toward towards under
+		underneath unlike until up upon versus via with within without`),
+	TitleAPA: shortMinorWords,
+}
+
+func wordSet(words string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
+
+// titleize implements Titleize.
+func titleize(s string, style TitleStyle) string {
+	if strings.IndexFunc(s, unicode.IsLower) < 0 {
+		// Shouted input has no case to preserve.
+		s = lowerCase(s)
+	}
+	minor := minorWords[style]
+
+	type token struct {
+		text  string
+		space bool
+	}
+	tokens := []token{}
+	for s != "" {
+		space := unicode.IsSpace([]rune(s)[0])
+		i := strings.IndexFunc(s, func(r rune) bool {
+			return unicode.IsSpace(r) != space
+		})
+		if i < 0 {
+			i = len(s)
+		}
+		tokens = append(tokens, token{s[:i], space})
+		s = s[i:]
+	}
+	lastWord := -1
+	for i, t := range tokens {
+		if !t.space && hasLetterOrDigit(t.text) {
+			lastWord = i
+		}
+	}
+
+	var b strings.Builder
+	first := true
+	for i, t := range tokens {
+		if t.space || !hasLetterOrDigit(t.text) {
+			b.WriteString(t.text)
+			if !t.space {
+				// A dash standing alone starts a new part of the title.
+				first = first || strings.ContainsAny(t.text, "—–-:")
+			}
+			continue
+		}
+		last := i == lastWord || strings.ContainsAny(t.text[len(t.text)-1:], ":?!")
+		// APA only capitalizes minor words that start a title or subtitle.
+		capitalizeLast := last && style != TitleAPA
+		parts := strings.Split(t.text, "-")
+		for j, part := range parts {
+			if j > 0 {
+				b.WriteString("-")
+			}
+			core := strings.ToLower(strings.TrimFunc(part, func(r rune) bool {
+				return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+			}))
+			switch {
+			case hasInnerUpper(part) || strings.Contains(strings.Trim(part, "."), "."):
+				// Acronyms, brand names like "iPhone" and domains.
+				b.WriteString(part)
+			case (j == 0 && first) || (j == len(parts)-1 && capitalizeLast) || !minor[core]:
+				b.WriteString(capitalizeFirst(part))
+			default:
+				b.WriteString(lowerCase(part))
+			}
+		}
+		first = last
+	}
+	return b.String()
+}
+
+func hasLetterOrDigit(s string) bool {
+	return strings.IndexFunc(s, func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}) >= 0
+}
+
+// hasInnerUpper reports whether s has an upper case letter after its first
+// letter, as in "NASA", "iPhone" and "McDonald".
+func hasInnerUpper(s string) bool {
+	i := strings.IndexFunc(s, unicode.IsLetter)
+	if i < 0 {
+		return false
+	}
+	_, size := utf8.DecodeRuneInString(s[i:])
+	return strings.IndexFunc(s[i+size:], isUpperOrTitle) >= 0
+}
+
+// capitalizeFirst title cases the first letter of s, leaving the rest.
+func capitalizeFirst(s string) string {
+	i := strings.IndexFunc(s, unicode.IsLetter)
+	if i < 0 {
+		return s
+	}
+	r, size := utf8.DecodeRuneInString(s[i:])
+	first, ok := specialTitle[r]
+	if !ok {
+		first = string(unicode.ToTitle(r))
+	}
+	return s[:i] + first + s[i+size:]
+}
@@ -0,0 +1,105 @@
+package str
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Logger receives diagnostic messages, such as the hints given by
+// EscapeHTML and UnescapeHTML that point to their standard library
+// equivalents. *log.Logger satisfies Logger.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// TraceEvent describes one stage of a traced pipeline.
+type TraceEvent struct {
+	// Index is the position of the stage in its pipeline, starting at 0.
+	Index int
+	// Name identifies the stage. Stages of a pipeline built by
+	// ParsePipeline are named by their source, such as `chompLeft "bc"`.
+	// Other stages are named as in PipeError, except that a PipeE stage
+	// wrapped by Lift or Named reports its given name only when it fails;
+	// use PipeStages to name every stage.
+	Name string
+	// Input and Output are the string passed to and returned by the stage.
+	Input  string
+	Output string
+	// Duration is the time the stage took to run.
+	Duration time.Duration
+	// Err is the error returned by a failing PipeE stage.
+	Err error
+}
+
+// Tracer receives a TraceEvent after each stage run by Pipe, PipeE,
+// PipeStages and the Filters returned by Compose and ParsePipeline. A Filter
+// nested in another pipeline reports its own stages before the stage that
+// contains it.
+type Tracer func(TraceEvent)
+
+var diagnostics struct {
+	sync.RWMutex
+	logger Logger
+	// tracer holds a Tracer. It is read on every Pipe, so it is atomic
+	// rather than guarded by the mutex.
+	tracer atomic.Value
+}
+
+// SetLogger directs diagnostic messages to l. A nil l discards them unless
+// Verbose is set.
+func SetLogger(l Logger) {
+	diagnostics.Lock()
+	defer diagnostics.Unlock()
+	diagnostics.logger = l
+}
+
+// SetTracer installs t to trace every pipeline. Tracers see each stage's
+// input and output, so they are meant for debugging. A nil t disables
+// tracing.
+func SetTracer(t Tracer) {
+	diagnostics.tracer.Store(t)
+}
+
+func currentTracer() Tracer {
+	t, _ := diagnostics.tracer.Load().(Tracer)
+	return t
+}
+
+// deprecated reports that function name should be replaced by replacement.
+func deprecated(name, replacement string) {
+	diagnostics.RLock()
+	logger := diagnostics.logger
+	diagnostics.RUnlock()
+	msg := "Use " + replacement + " instead of " + name
+	if logger != nil {
+		logger.Printf("%s", msg)
+	} else if Verbose {
+		fmt.Println(msg)
+	}
+}
+
+// pipe is Pipe with optional stage names, which default to funcName.
+func pipe(s string, names []string, funcs []func(string) string) string {
+	t := currentTracer()
+	if t == nil {
+		for _, fn := range funcs {
+			s = fn(s)
+		}
+		return s
+	}
+	for i, fn := range funcs {
+		start := time.Now()
+		result := fn(s)
+		event := TraceEvent{Index: i, Input: s, Output: result, Duration: time.Since(start)}
+		if i < len(names) {
+			event.Name = names[i]
+		} else {
+			event.Name = funcName(fn)
+		}
+		t(event)
+		s = result
+	}
+	return s
+}
@@ -0,0 +1,87 @@
+package str
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// caseWords splits s into words for the case converters and reports
+// whether s has a leading marker: a "-", "_" or upper case letter before
+// the first word. Dasherize and Underscore keep the marker as a leading
+// separator and Camelize capitalizes the first word for it, so each
+// converter round-trips through the others.
+func (c *casing) caseWords(s string) ([]string, bool) {
+	s = strings.TrimSpace(s)
+	r, _ := utf8.DecodeRuneInString(s)
+	return c.words(s), r == '-' || r == '_' || isUpperOrTitle(r)
+}
+
+// joinWords joins words with sep after applying fn to each.
+func joinWords(words []string, sep string, fn func(string) string) string {
+	for i, w := range words {
+		words[i] = fn(w)
+	}
+	return strings.Join(words, sep)
+}
+
+// isWordRune reports whether runes[i] belongs to a word: a letter, digit or
+// mark, or an apostrophe between two letters.
+func isWordRune(runes []rune, i int) bool {
+	r := runes[i]
+	if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) {
+		return true
+	}
+	return (r == '\'' || r == '’') && i > 0 && i+1 < len(runes) &&
+		unicode.IsLetter(runes[i-1]) && unicode.IsLetter(runes[i+1])
+}
+
+// words implements Words and WordsLocale.
+func (c *casing) words(s string) []string {
+	words := []string{}
+	runes := []rune(s)
+	start := -1
+	for i := range runes {
+		if !isWordRune(runes, i) {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		} else if c.isWordBoundary(runes, i) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// isWordBoundary reports whether a new word starts at runes[i], which
+// follows another word rune.
+func (c *casing) isWordBoundary(runes []rune, i int) bool {
+	prev, r := runes[i-1], runes[i]
+	if !isUpperOrTitle(r) {
+		return false
+	}
+	if unicode.IsLower(prev) || unicode.IsDigit(prev) {
+		return true
+	}
+	if c != nil && c.digraph != nil && c.digraph(runes, i) &&
+		(i == 1 || !isWordRune(runes, i-2) || c.isWordBoundary(runes, i-1)) {
+		// A digraph starting a word, as in "IJssel".
+		return false
+	}
+	return isUpperOrTitle(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+}
+
+// isUpperOrTitle reports whether r is an upper case letter or a title case
+// digraph such as "ǅ".
+func isUpperOrTitle(r rune) bool {
+	return unicode.IsUpper(r) || unicode.IsTitle(r)
+}